@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	anthropicEndpoint = "https://api.anthropic.com/v1/messages"
+	anthropicVersion  = "2023-06-01"
+)
+
+// Request structures
+type AnthropicRequest struct {
+	Model     string    `json:"model"`
+	System    string    `json:"system,omitempty"`
+	Messages  []Message `json:"messages"`
+	MaxTokens int       `json:"max_tokens"`
+	Stream    bool      `json:"stream,omitempty"`
+}
+
+// Response structures
+type AnthropicResponse struct {
+	Content []AnthropicContent `json:"content"`
+	Error   *APIError          `json:"error,omitempty"`
+}
+
+type AnthropicContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// AnthropicStreamEvent covers the fields of the streaming events we care about
+// (content_block_delta and error); all other event types are ignored.
+type AnthropicStreamEvent struct {
+	Type  string           `json:"type"`
+	Delta AnthropicContent `json:"delta"`
+	Error *APIError        `json:"error,omitempty"`
+}
+
+// anthropicGenerator talks to the Anthropic Messages API.
+type anthropicGenerator struct {
+	endpoint string
+	apiKey   string
+}
+
+func (g *anthropicGenerator) Generate(ctx context.Context, genReq GenerateRequest) (string, error) {
+	system := genReq.SystemPrompt
+	if genReq.Schema != nil {
+		// The Messages API has no response_format; spell the schema out instead.
+		schema, err := json.Marshal(genReq.Schema.Schema)
+		if err != nil {
+			return "", fmt.Errorf("schema JSON 생성 오류: %w", err)
+		}
+		system += "\n\nYour entire reply must be a single JSON object (no prose, no code fences) that validates against this JSON schema:\n" + string(schema)
+	}
+	reqBody := AnthropicRequest{
+		Model:     genReq.Model,
+		System:    system,
+		Messages:  []Message{{Role: "user", Content: genReq.UserPrompt}},
+		MaxTokens: maxTokensOrDefault(genReq.MaxTokens),
+		Stream:    genReq.OnDelta != nil,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("request JSON 생성 오류: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", g.endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("HTTP 요청 생성 오류: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", g.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	client := &http.Client{Timeout: 130 * time.Second}
+	if reqBody.Stream {
+		client.Timeout = streamTimeout
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", newNetworkError(ctx, fmt.Errorf("Anthropic API 요청 오류: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if reqBody.Stream && resp.StatusCode == http.StatusOK {
+		text, err := readAnthropicStream(resp.Body, genReq.OnDelta)
+		if err != nil {
+			return text, newNetworkError(ctx, err)
+		}
+		return text, nil
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", newNetworkError(ctx, fmt.Errorf("응답 읽기 오류: %w", err))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newHTTPError(resp, respBody)
+	}
+
+	var msgResp AnthropicResponse
+	if err := json.Unmarshal(respBody, &msgResp); err != nil {
+		return "", fmt.Errorf("응답 JSON 파싱 오류: %w. 응답: %s", err, string(respBody))
+	}
+
+	if msgResp.Error != nil {
+		return "", fmt.Errorf("API 오류: %s (%s)", msgResp.Error.Message, msgResp.Error.Type)
+	}
+
+	var sb strings.Builder
+	for _, c := range msgResp.Content {
+		if c.Type == "text" {
+			sb.WriteString(c.Text)
+		}
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("API가 비어있는 응답을 반환했습니다")
+	}
+
+	return sb.String(), nil
+}
+
+// readAnthropicStream consumes a Messages API SSE stream, forwarding every
+// text delta to onDelta and returning the concatenated text. A stream that
+// ends before message_stop was cut off.
+func readAnthropicStream(body io.Reader, onDelta func(string)) (string, error) {
+	var sb strings.Builder
+	done := false
+	err := readSSE(body, func(_, data string) error {
+		var ev AnthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return fmt.Errorf("스트림 JSON 파싱 오류: %w. 데이터: %s", err, data)
+		}
+		switch ev.Type {
+		case "error":
+			if ev.Error != nil {
+				return fmt.Errorf("API 오류: %s (%s)", ev.Error.Message, ev.Error.Type)
+			}
+			return fmt.Errorf("API 오류: %s", data)
+		case "content_block_delta":
+			if ev.Delta.Type == "text_delta" && ev.Delta.Text != "" {
+				sb.WriteString(ev.Delta.Text)
+				onDelta(ev.Delta.Text)
+			}
+		case "message_stop":
+			done = true
+			return io.EOF
+		}
+		return nil
+	})
+	if err == nil && !done {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return sb.String(), fmt.Errorf("스트림 읽기 오류: %w", err)
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("API가 비어있는 응답을 반환했습니다")
+	}
+	return sb.String(), nil
+}
@@ -6,19 +6,43 @@ import (
 )
 
 type APIConfig struct {
-	APIKey string `json:"chatgpt_api_key"`
+	APIKey          string   `json:"chatgpt_api_key"`
+	AnthropicAPIKey string   `json:"anthropic_api_key,omitempty"`
+	GeminiAPIKey    string   `json:"gemini_api_key,omitempty"`
+	LocalBaseURL    string   `json:"local_base_url,omitempty"`
+	LocalModels     []string `json:"local_models,omitempty"`
+	MaxRetries      *int     `json:"max_retries,omitempty"`
+	ChunkSize       int      `json:"chunk_size,omitempty"`
+	Concurrency     int      `json:"concurrency,omitempty"`
+	// StructuredOutput requests JSON-schema output instead of free-text question blocks.
+	StructuredOutput bool `json:"structured_output,omitempty"`
+	MaxRegenerations *int `json:"max_regenerations,omitempty"`
+	// AnswerDistribution is the relative share of ①–⑤ as correct answers, e.g. [20,20,20,20,20].
+	AnswerDistribution []int `json:"answer_distribution,omitempty"`
+	AnswerSeed         int64 `json:"answer_seed,omitempty"`
+	// AnswerExplanations adds the tested word and its meaning to every answer of
+	// the separately saved answer key.
+	AnswerExplanations bool `json:"answer_explanations,omitempty"`
+	// Print sets up the printable HTML and PDF export.
+	Print PrintConfig `json:"print"`
+	// TemplatesDir holds prompt templates that override or add to the built-in question types.
+	TemplatesDir string `json:"templates_dir,omitempty"`
 }
 
-func loadAPIKey() (string, error) {
-	data, err := os.ReadFile("api.json")
+func loadAPIConfig() (APIConfig, error) {
+	return loadAPIConfigFile("api.json")
+}
+
+func loadAPIConfigFile(path string) (APIConfig, error) {
+	var config APIConfig
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return config, err
 	}
 
-	var config APIConfig
 	if err := json.Unmarshal(data, &config); err != nil {
-		return "", err
+		return config, err
 	}
 
-	return config.APIKey, nil
+	return config, nil
 }
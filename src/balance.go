@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// balanceAnswers moves the correct choice of every question to a position drawn
+// from the target distribution and shuffles the distractors around it. weights
+// gives the relative share of ①–⑤ (nil means an even 20% each); the counts are
+// exact up to rounding. Questions without a known answer, and those whose choices
+// are underlined in the passage, are left untouched.
+func balanceAnswers(questions []Question, weights []int, seed int64) {
+	rng := rand.New(rand.NewSource(seed))
+
+	var idx []int
+	for i, q := range questions {
+		if q.Answer >= 0 && q.Answer < len(q.Choices) && len(q.Choices) == numChoices && !q.Underlined {
+			idx = append(idx, i)
+		}
+	}
+	targets := targetPositions(len(idx), weights)
+	rng.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+
+	for k, i := range idx {
+		q := &questions[i]
+		correct := q.Choices[q.Answer]
+		var distractors []string
+		for j, c := range q.Choices {
+			if j != q.Answer {
+				distractors = append(distractors, c)
+			}
+		}
+		rng.Shuffle(len(distractors), func(a, b int) { distractors[a], distractors[b] = distractors[b], distractors[a] })
+
+		choices := make([]string, 0, numChoices)
+		choices = append(choices, distractors[:targets[k]]...)
+		choices = append(choices, correct)
+		choices = append(choices, distractors[targets[k]:]...)
+		q.Choices = choices
+		q.Answer = targets[k]
+	}
+}
+
+// targetPositions returns n answer positions distributed according to weights,
+// using the largest remainder method so the counts add up to exactly n.
+func targetPositions(n int, weights []int) []int {
+	if len(weights) != numChoices {
+		weights = []int{1, 1, 1, 1, 1}
+	}
+	positions := make([]int, 0, n)
+	for pos, c := range apportion(n, weights) {
+		for ; c > 0; c-- {
+			positions = append(positions, pos)
+		}
+	}
+	return positions
+}
+
+// apportion splits n into len(weights) counts proportional to weights with the
+// largest remainder method, so they add up to exactly n. Negative weights count
+// as zero; if all are zero the split is even.
+func apportion(n int, weights []int) []int {
+	total := 0
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	if total == 0 {
+		weights = make([]int, len(weights))
+		for i := range weights {
+			weights[i] = 1
+		}
+		total = len(weights)
+	}
+
+	counts := make([]int, len(weights))
+	remainders := make([]int, len(weights))
+	assigned := 0
+	for i, w := range weights {
+		if w < 0 {
+			w = 0
+		}
+		counts[i] = n * w / total
+		remainders[i] = n * w % total
+		assigned += counts[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for k := 0; assigned < n; k++ {
+		counts[order[k%len(order)]]++
+		assigned++
+	}
+	return counts
+}
+
+// answerDistribution counts how often each position is the correct answer.
+func answerDistribution(questions []Question) [numChoices]int {
+	var counts [numChoices]int
+	for _, q := range questions {
+		if q.Answer >= 0 && q.Answer < numChoices {
+			counts[q.Answer]++
+		}
+	}
+	return counts
+}
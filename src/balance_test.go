@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestApportion(t *testing.T) {
+	tests := []struct {
+		n       int
+		weights []int
+		want    []int
+	}{
+		{10, []int{1, 1, 1, 1, 1}, []int{2, 2, 2, 2, 2}},
+		{7, []int{1, 1, 1, 1, 1}, []int{2, 2, 1, 1, 1}},
+		{10, []int{40, 30, 30}, []int{4, 3, 3}},
+		{11, []int{40, 30, 30}, []int{5, 3, 3}},
+		{3, []int{1, 0, 2}, []int{1, 0, 2}},
+		{4, []int{-5, 1}, []int{0, 4}},
+		{5, []int{0, 0}, []int{3, 2}},
+		{0, []int{1, 2}, []int{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.n, tt.weights), func(t *testing.T) {
+			got := apportion(tt.n, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apportion(%d, %v) = %v, want %v", tt.n, tt.weights, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBalanceAnswers(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		weights []int
+		want    [numChoices]int
+	}{
+		{"even", 10, nil, [numChoices]int{2, 2, 2, 2, 2}},
+		{"weighted", 10, []int{0, 1, 1, 0, 3}, [numChoices]int{0, 2, 2, 0, 6}},
+		{"invalid weights are even", 5, []int{1, 2}, [numChoices]int{1, 1, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var questions []Question
+			for i := 0; i < tt.n; i++ {
+				questions = append(questions, Question{
+					Number:  i + 1,
+					Choices: []string{fmt.Sprint("right", i), "w1", "w2", "w3", "w4"},
+					Answer:  0,
+				})
+			}
+			balanceAnswers(questions, tt.weights, 42)
+			if got := answerDistribution(questions); got != tt.want {
+				t.Errorf("distribution = %v, want %v", got, tt.want)
+			}
+			for i, q := range questions {
+				if q.Choices[q.Answer] != fmt.Sprint("right", i) {
+					t.Errorf("question %d: answer %d is %q", q.Number, q.Answer, q.Choices[q.Answer])
+				}
+				if hasDuplicateChoice(q.Choices) || len(q.Choices) != numChoices {
+					t.Errorf("question %d: choices %q", q.Number, q.Choices)
+				}
+			}
+		})
+	}
+}
+
+func TestBalanceAnswersSkips(t *testing.T) {
+	questions := []Question{
+		{Choices: []string{"a", "b", "c", "d", "e"}, Answer: -1},
+		{Choices: []string{"a", "b", "c", "d", "e"}, Answer: 2, Underlined: true},
+		{AnswerText: "(a) bank", Answer: -1},
+	}
+	want := append([]Question(nil), questions...)
+	balanceAnswers(questions, nil, 1)
+	if !reflect.DeepEqual(questions, want) {
+		t.Errorf("questions changed: %+v", questions)
+	}
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -13,10 +16,23 @@ const openAIEndpoint = "https://api.openai.com/v1/chat/completions"
 
 // Request structures
 type ChatRequest struct {
-	Model       string    `json:"model"`
-	Messages    []Message `json:"messages"`
-	Temperature float32   `json:"temperature"`
-	MaxCompletionTokens   int       `json:"max_completion_tokens,omitempty"`
+	Model               string          `json:"model"`
+	Messages            []Message       `json:"messages"`
+	Temperature         float32         `json:"temperature"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Stream              bool            `json:"stream,omitempty"`
+	ResponseFormat      *ResponseFormat `json:"response_format,omitempty"`
+}
+
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+type JSONSchema struct {
+	Name   string         `json:"name"`
+	Strict bool           `json:"strict"`
+	Schema map[string]any `json:"schema"`
 }
 
 type Message struct {
@@ -26,12 +42,13 @@ type Message struct {
 
 // Response structures
 type ChatResponse struct {
-	Choices []Choice `json:"choices"`
+	Choices []Choice  `json:"choices"`
 	Error   *APIError `json:"error,omitempty"`
 }
 
 type Choice struct {
 	Message Message `json:"message"`
+	Delta   Message `json:"delta"`
 }
 
 type APIError struct {
@@ -40,20 +57,30 @@ type APIError struct {
 	Code    string `json:"code"`
 }
 
+// openAIGenerator talks to the OpenAI chat completions API or any server that mimics it.
+// An empty apiKey is allowed for local servers, which usually ignore authentication.
+type openAIGenerator struct {
+	endpoint string
+	apiKey   string
+}
 
-func callChatGPT(apiKey, model, systemPrompt, userPrompt string) (string, error) {
-	if apiKey == "" {
-		return "", fmt.Errorf("OpenAI API 키가 설정되지 않았습니다. api.json 파일을 확인하세요")
-	}
+func (g *openAIGenerator) Generate(ctx context.Context, req GenerateRequest) (string, error) {
+	return callChatGPT(ctx, g.endpoint, g.apiKey, req)
+}
 
+func callChatGPT(ctx context.Context, endpoint, apiKey string, genReq GenerateRequest) (string, error) {
 	reqBody := ChatRequest{
-		Model: model,
+		Model: genReq.Model,
 		Messages: []Message{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: userPrompt},
+			{Role: "system", Content: genReq.SystemPrompt},
+			{Role: "user", Content: genReq.UserPrompt},
 		},
-		Temperature: 1.0,
-		MaxCompletionTokens:   8192,
+		Temperature:         1.0,
+		MaxCompletionTokens: maxTokensOrDefault(genReq.MaxTokens),
+		Stream:              genReq.OnDelta != nil,
+	}
+	if genReq.Schema != nil {
+		reqBody.ResponseFormat = &ResponseFormat{Type: "json_schema", JSONSchema: genReq.Schema}
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -61,31 +88,48 @@ func callChatGPT(apiKey, model, systemPrompt, userPrompt string) (string, error)
 		return "", fmt.Errorf("request JSON 생성 오류: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", openAIEndpoint, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("HTTP 요청 생성 오류: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
 
 	client := &http.Client{Timeout: 130 * time.Second}
+	if reqBody.Stream {
+		client.Timeout = streamTimeout
+	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("ChatGPT API 요청 오류: %w", err)
+		return "", newNetworkError(ctx, fmt.Errorf("ChatGPT API 요청 오류: %w", err))
 	}
 	defer resp.Body.Close()
 
+	if reqBody.Stream && resp.StatusCode == http.StatusOK {
+		text, err := readChatGPTStream(resp.Body, genReq.OnDelta)
+		if err != nil {
+			return text, newNetworkError(ctx, err)
+		}
+		return text, nil
+	}
+
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("응답 읽기 오류: %w", err)
+		return "", newNetworkError(ctx, fmt.Errorf("응답 읽기 오류: %w", err))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newHTTPError(resp, respBody)
 	}
 
 	var chatResp ChatResponse
 	if err := json.Unmarshal(respBody, &chatResp); err != nil {
 		return "", fmt.Errorf("응답 JSON 파싱 오류: %w. 응답: %s", err, string(respBody))
 	}
-	
+
 	if chatResp.Error != nil {
 		return "", fmt.Errorf("API 오류: %s (%s)", chatResp.Error.Message, chatResp.Error.Type)
 	}
@@ -96,3 +140,129 @@ func callChatGPT(apiKey, model, systemPrompt, userPrompt string) (string, error)
 
 	return chatResp.Choices[0].Message.Content, nil
 }
+
+// readChatGPTStream consumes a chat completions SSE stream, forwarding every
+// content delta to onDelta and returning the concatenated text. A stream that
+// ends before [DONE] was cut off.
+func readChatGPTStream(body io.Reader, onDelta func(string)) (string, error) {
+	var sb strings.Builder
+	done := false
+	err := readSSE(body, func(_, data string) error {
+		if data == "[DONE]" {
+			done = true
+			return io.EOF
+		}
+		var chunk ChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("스트림 JSON 파싱 오류: %w. 데이터: %s", err, data)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("API 오류: %s (%s)", chunk.Error.Message, chunk.Error.Type)
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			sb.WriteString(chunk.Choices[0].Delta.Content)
+			onDelta(chunk.Choices[0].Delta.Content)
+		}
+		return nil
+	})
+	if err == nil && !done {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return sb.String(), fmt.Errorf("스트림 읽기 오류: %w", err)
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("API가 비어있는 응답을 반환했습니다")
+	}
+	return sb.String(), nil
+}
+
+// questionSchema describes the structured output of one request: the questions
+// with their choices and the index of the correct one.
+var questionSchema = &JSONSchema{
+	Name:   "vocabulary_questions",
+	Strict: true,
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"questions": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"word":    map[string]any{"type": "string"},
+						"pos":     map[string]any{"type": "string"},
+						"sense":   map[string]any{"type": "string"},
+						"prompt":  map[string]any{"type": "string"},
+						"context": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+						"choices": map[string]any{
+							"type":     "array",
+							"items":    map[string]any{"type": "string"},
+							"minItems": numChoices,
+							"maxItems": numChoices,
+						},
+						"answer_index": map[string]any{"type": "integer", "minimum": 0, "maximum": numChoices - 1},
+					},
+					"required":             []string{"word", "pos", "sense", "prompt", "context", "choices", "answer_index"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"questions"},
+		"additionalProperties": false,
+	},
+}
+
+type structuredQuestion struct {
+	Word        string   `json:"word"`
+	POS         string   `json:"pos"`
+	Sense       string   `json:"sense"`
+	Prompt      string   `json:"prompt"`
+	Context     []string `json:"context"`
+	Choices     []string `json:"choices"`
+	AnswerIndex int      `json:"answer_index"`
+}
+
+// decodeStructuredQuestions decodes a questionSchema response into questions
+// numbered from 1. Markdown code fences some models add are stripped first.
+func decodeStructuredQuestions(text string) ([]Question, error) {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+
+	var resp struct {
+		Questions []structuredQuestion `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		return nil, fmt.Errorf("구조화 응답 JSON 파싱 오류: %w", err)
+	}
+
+	var questions []Question
+	var errs []error
+	for i, sq := range resp.Questions {
+		q := Question{
+			Number:  i + 1,
+			Prompt:  strings.TrimSpace(sq.Prompt),
+			Context: sq.Context,
+			Choices: sq.Choices,
+			Answer:  sq.AnswerIndex,
+			Word:    strings.TrimSpace(sq.Word),
+			POS:     strings.TrimSpace(sq.POS),
+			Sense:   strings.TrimSpace(sq.Sense),
+		}
+		if len(q.Choices) != numChoices {
+			errs = append(errs, &QuestionParseError{Block: i + 1, Number: q.Number, Reason: fmt.Sprintf("선택지가 %d개입니다 (5개 필요)", len(q.Choices))})
+			continue
+		}
+		if q.Answer < 0 || q.Answer >= numChoices {
+			errs = append(errs, &QuestionParseError{Block: i + 1, Number: q.Number, Reason: fmt.Sprintf("answer_index %d가 범위를 벗어났습니다", q.Answer)})
+			q.Answer = -1
+		}
+		questions = append(questions, q)
+	}
+	if len(questions) == 0 && len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("구조화 응답에 문제가 없습니다"))
+	}
+	return questions, errors.Join(errs...)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDecodeStructuredQuestions(t *testing.T) {
+	valid := `{"word": "bank", "pos": "n.", "sense": "둑", "prompt": "빈칸에 알맞은 것은?", "context": ["He sat on the ___ of the river."], "choices": ["bank", "run", "fair", "bear", "light"], "answer_index": 0}`
+	tests := []struct {
+		name    string
+		text    string
+		want    []Question
+		wantErr []string
+	}{
+		{
+			name: "valid",
+			text: `{"questions": [` + valid + `]}`,
+			want: []Question{{Number: 1, Prompt: "빈칸에 알맞은 것은?", Context: []string{"He sat on the ___ of the river."}, Choices: []string{"bank", "run", "fair", "bear", "light"}, Answer: 0, Word: "bank", POS: "n.", Sense: "둑"}},
+		},
+		{
+			name: "code fence",
+			text: "```json\n{\"questions\": [" + valid + "]}\n```",
+			want: []Question{{Number: 1, Prompt: "빈칸에 알맞은 것은?", Context: []string{"He sat on the ___ of the river."}, Choices: []string{"bank", "run", "fair", "bear", "light"}, Answer: 0, Word: "bank", POS: "n.", Sense: "둑"}},
+		},
+		{
+			name:    "wrong number of choices",
+			text:    `{"questions": [{"word": "run", "prompt": "q", "context": [], "choices": ["a", "b", "c", "d"], "answer_index": 1}, ` + valid + `]}`,
+			want:    []Question{{Number: 2, Prompt: "빈칸에 알맞은 것은?", Context: []string{"He sat on the ___ of the river."}, Choices: []string{"bank", "run", "fair", "bear", "light"}, Answer: 0, Word: "bank", POS: "n.", Sense: "둑"}},
+			wantErr: []string{"1번째 블록(문제 1): 선택지가 4개입니다"},
+		},
+		{
+			name:    "answer out of range",
+			text:    `{"questions": [{"word": "run", "prompt": "q", "context": [], "choices": ["a", "b", "c", "d", "e"], "answer_index": 5}, {"word": "fair", "prompt": "q", "context": [], "choices": ["a", "b", "c", "d", "e"], "answer_index": -1}]}`,
+			want:    []Question{{Number: 1, Prompt: "q", Context: []string{}, Choices: []string{"a", "b", "c", "d", "e"}, Answer: -1, Word: "run"}, {Number: 2, Prompt: "q", Context: []string{}, Choices: []string{"a", "b", "c", "d", "e"}, Answer: -1, Word: "fair"}},
+			wantErr: []string{"answer_index 5가 범위를 벗어났습니다", "answer_index -1가 범위를 벗어났습니다"},
+		},
+		{name: "no questions", text: `{"questions": []}`, wantErr: []string{"구조화 응답에 문제가 없습니다"}},
+		{name: "not json", text: "1. 다음 빈칸에…", wantErr: []string{"구조화 응답 JSON 파싱 오류"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeStructuredQuestions(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("questions = %#v, want %#v", got, tt.want)
+			}
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			for _, want := range tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+// Strict structured output requires every property, and the decoder must read each of them.
+func TestQuestionSchemaMatchesDecoder(t *testing.T) {
+	item := questionSchema.Schema["properties"].(map[string]any)["questions"].(map[string]any)["items"].(map[string]any)
+	var props []string
+	for name := range item["properties"].(map[string]any) {
+		props = append(props, name)
+	}
+	required := append([]string(nil), item["required"].([]string)...)
+	var fields []string
+	typ := reflect.TypeOf(structuredQuestion{})
+	for i := 0; i < typ.NumField(); i++ {
+		fields = append(fields, typ.Field(i).Tag.Get("json"))
+	}
+	sort.Strings(props)
+	sort.Strings(required)
+	sort.Strings(fields)
+	if !reflect.DeepEqual(props, required) || !reflect.DeepEqual(props, fields) {
+		t.Errorf("properties %q, required %q, decoded fields %q", props, required, fields)
+	}
+	choices := item["properties"].(map[string]any)["choices"].(map[string]any)
+	answer := item["properties"].(map[string]any)["answer_index"].(map[string]any)
+	if choices["minItems"] != numChoices || choices["maxItems"] != numChoices || answer["maximum"] != numChoices-1 {
+		t.Errorf("choices %v, answer_index %v", choices, answer)
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// Exit codes of the headless mode.
+const (
+	exitOK     = 0
+	exitError  = 1
+	exitUsage  = 2
+	exitIssues = 3 // output was written, but validation issues remain
+)
+
+var defaultModels = map[string]string{
+	providerOpenAI:    "gpt-5-mini",
+	providerAnthropic: "claude-sonnet-4-5",
+	providerGemini:    "gemini-2.5-flash",
+	providerLocal:     "llama3.1",
+}
+
+// cliGenerator makes the Generator of a headless run.
+var cliGenerator = newGenerator
+
+// runCLI runs a subcommand without the terminal UI and returns the process exit code.
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: vocab-maker generate [flags]")
+		return exitUsage
+	}
+	switch args[0] {
+	case "generate":
+		return runGenerateCLI(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprintln(stderr, "usage: vocab-maker generate [flags]\n\nRun 'vocab-maker generate -h' for the list of flags.")
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "unknown command %q\nusage: vocab-maker generate [flags]\n", args[0])
+	return exitUsage
+}
+
+func runGenerateCLI(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	input := fs.String("i", "", "vocabulary file ('-' for stdin)")
+	output := fs.String("o", "", "output file; .html and .pdf make a printable paper (default: stdout)")
+	keyOutput := fs.String("key", "", "write the answer key to this file and only the student paper to -o")
+	explain := fs.Bool("explain", false, "add the tested word and meaning to every answer (default from answer_explanations)")
+	qType := fs.String("type", "빈칸 추론", "question type: "+strings.Join(questionTypeIDs(), ", "))
+	mixSpec := fs.String("mix", "", "mixed test, e.g. \""+defaultMixes[false]+"\" (overrides -type)")
+	provider := fs.String("provider", "", "provider: openai, anthropic, gemini, local (default: first configured; offline types need none)")
+	modelID := fs.String("model", "", "model id (default depends on provider)")
+	sentences := fs.Int("sentences", 2, "context sentences per question (빈칸 추론)")
+	difficulty := fs.String("difficulty", "", "difficulty: "+strings.Join(difficultyNames(), ", ")+" (default: the original challenging style)")
+	grade := fs.Int("grade", 0, "target school grade, 7 (중1) to 12 (고3) (default from -difficulty)")
+	configPath := fs.String("config", "api.json", "API config file")
+	chunkSize := fs.Int("chunk-size", 0, "words per request (default from config or 25)")
+	concurrency := fs.Int("concurrency", 0, "parallel requests (default from config or 3)")
+	structured := fs.Bool("structured", false, "request JSON-schema structured output")
+	forms := fs.Int("forms", 1, fmt.Sprintf("number of test forms A, B, ... with shuffled question and choice order (1–%d)", maxForms))
+	seed := fs.Int64("seed", 0, "seed for word order and answer positions (0: random)")
+	font := fs.String("font", "", "TrueType font embedded in PDF output (default from print.font or a Korean system font)")
+	quiet := fs.Bool("q", false, "do not report progress on stderr")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *input == "" {
+		fmt.Fprintln(stderr, "error: -i is required")
+		fs.Usage()
+		return exitUsage
+	}
+	if *forms < 1 || *forms > maxForms {
+		fmt.Fprintf(stderr, "error: -forms must be between 1 and %d\n", maxForms)
+		return exitUsage
+	}
+	learner, err := resolveLearner(*difficulty, *grade)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitUsage
+	}
+	// Templates may add question types, so load them before resolving -type.
+	cfg, cfgErr := loadAPIConfigFile(*configPath)
+	if err := loadQuestionTypes(cfg.TemplatesDir); err != nil {
+		fmt.Fprintf(stderr, "warning: prompt templates: %v\n", err)
+	}
+	var mix []MixPart
+	questionType, offline := mixedQuestionType, true
+	if *mixSpec != "" {
+		var err error
+		if mix, err = parseMix(*mixSpec); err != nil {
+			fmt.Fprintf(stderr, "error: -mix: %v (available: %s)\n", err, strings.Join(questionTypeIDs(), ", "))
+			return exitUsage
+		}
+		for _, p := range mix {
+			if qt, _ := findQuestionType(p.QuestionType); qt.Local == nil {
+				offline = false
+			}
+		}
+	} else {
+		var ok bool
+		if questionType, ok = resolveQuestionType(*qType); !ok {
+			fmt.Fprintf(stderr, "error: unknown question type %q (available: %s)\n", *qType, strings.Join(questionTypeIDs(), ", "))
+			return exitUsage
+		}
+		qt, _ := findQuestionType(questionType)
+		offline = qt.Local != nil
+	}
+	if offline {
+		*provider, *modelID = providerOffline, ""
+	} else if *provider == providerOffline {
+		fmt.Fprintf(stderr, "error: question type %q needs a model; offline types: %s\n", questionType, strings.Join(offlineTypeIDs(), ", "))
+		return exitUsage
+	}
+	if cfgErr != nil && !(offline && os.IsNotExist(cfgErr)) {
+		// Offline question types work without a config file.
+		fmt.Fprintf(stderr, "error: reading %s: %v\n", *configPath, cfgErr)
+		return exitError
+	}
+	if *provider == "" {
+		providers := availableProviders(cfg)
+		if len(providers) == 0 {
+			fmt.Fprintf(stderr, "error: no API key configured in %s\n", *configPath)
+			return exitError
+		}
+		*provider = providers[0]
+	}
+	var gen Generator
+	if !offline {
+		if *modelID == "" {
+			*modelID = defaultModels[*provider]
+		}
+		var err error
+		if gen, err = cliGenerator(*provider, cfg); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return exitError
+		}
+	}
+
+	var content []byte
+	if *input == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(*input)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error: reading input: %v\n", err)
+		return exitError
+	}
+	parsed, diags, err := importVocab(*input, content)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+	if !*quiet {
+		for _, d := range diags {
+			fmt.Fprintf(stderr, "%s: %s\n", *input, d)
+		}
+	}
+	if len(parsed) == 0 {
+		fmt.Fprintf(stderr, "error: no vocabulary entries found in %s\n", *input)
+		return exitError
+	}
+
+	runSeed := *seed
+	if runSeed == 0 {
+		runSeed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(runSeed))
+	rng.Shuffle(len(parsed), func(i, j int) {
+		parsed[i], parsed[j] = parsed[j], parsed[i]
+	})
+
+	job := GenerationJob{
+		Model:            *modelID,
+		QuestionType:     questionType,
+		NumSentences:     *sentences,
+		Vocab:            parsed,
+		Learner:          learner,
+		Mix:              mix,
+		ChunkSize:        firstPositive(*chunkSize, cfg.ChunkSize),
+		Concurrency:      firstPositive(*concurrency, cfg.Concurrency),
+		Structured:       *structured || cfg.StructuredOutput,
+		MaxRegenerations: defaultMaxRegenerations,
+		AnswerWeights:    cfg.AnswerDistribution,
+		AnswerSeed:       runSeed,
+	}
+	if cfg.MaxRegenerations != nil {
+		job.MaxRegenerations = *cfg.MaxRegenerations
+	}
+	if !*quiet {
+		job.OnRetry = func(attempt, maxRetries int, wait time.Duration, err *GenerationError) {
+			fmt.Fprintf(stderr, "retry %d/%d in %s: %v\n", attempt, maxRetries, wait.Round(time.Second), err)
+		}
+		unit := "chunk"
+		if mix != nil {
+			unit = "part" // a mixed test reports each question type as it finishes
+		}
+		job.OnProgress = func(done, total int) {
+			fmt.Fprintf(stderr, "%s %d/%d done\n", unit, done, total)
+		}
+		job.OnRegenerate = func(round int, words []VocabPair) {
+			fmt.Fprintf(stderr, "regenerating %d words (round %d)\n", len(words), round)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	label := questionType
+	if mix != nil {
+		label = formatMix(mix)
+	}
+	if !*quiet {
+		if offline {
+			fmt.Fprintf(stderr, "building %d words offline (%s)...\n", len(parsed), label)
+		} else {
+			fmt.Fprintf(stderr, "generating %d words with %s/%s (%s)...\n", len(parsed), *provider, *modelID, label)
+		}
+	}
+	result, err := runGeneration(ctx, gen, job)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	text := result.Text
+	var testForms []TestForm
+	if *forms > 1 && len(result.Questions) > 0 {
+		testForms = makeForms(result.Questions, *forms, cfg.AnswerDistribution, runSeed)
+		text = formatForms(testForms)
+	}
+	paper, key, explained := paperAndKey(text, result.Questions, testForms, parsed, *explain || cfg.AnswerExplanations)
+	if (*explain || cfg.AnswerExplanations) && !explained && len(result.Questions) > 0 {
+		fmt.Fprintln(stderr, "warning: some question blocks could not be read; answers are written without explanations")
+	}
+	if *keyOutput == "" {
+		// Without -key the answers stay at the end of the paper.
+		paper, key = strings.TrimRight(paper, "\n")+"\n\n"+key, ""
+	}
+	if *font != "" {
+		cfg.Print.Font = *font
+	}
+	if *output == "" {
+		_, err = io.WriteString(stdout, paper)
+	} else {
+		err = writeDocument(*output, paper, cfg.Print)
+	}
+	if err == nil && *keyOutput != "" {
+		err = writeDocument(*keyOutput, key, cfg.Print)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error: writing output: %v\n", err)
+		return exitError
+	}
+
+	if result.ParseErr != nil {
+		fmt.Fprintf(stderr, "warning: malformed question blocks left out of the paper:\n%v\n", result.ParseErr)
+	}
+	for _, is := range result.Issues {
+		fmt.Fprintf(stderr, "warning: %s\n", is)
+	}
+	if result.ParseErr != nil || len(result.Issues) > 0 {
+		return exitIssues
+	}
+	return exitOK
+}
+
+// writeDocument saves text to path as text, HTML or PDF by its extension.
+func writeDocument(path, text string, cfg PrintConfig) error {
+	data, err := renderDocument(path, text, cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// questionTypeIDs lists the ids of all question types, offline ones included.
+func questionTypeIDs() []string {
+	var ids []string
+	for _, qt := range questionTypes {
+		ids = append(ids, qt.ID)
+	}
+	return ids
+}
+
+// offlineTypeIDs lists the question types that are built without a model.
+func offlineTypeIDs() []string {
+	var ids []string
+	for _, qt := range questionTypes {
+		if qt.Local != nil {
+			ids = append(ids, qt.ID)
+		}
+	}
+	return ids
+}
+
+// resolveQuestionType matches a question type name ignoring spaces, so "빈칸추론"
+// can be typed on the command line without quoting.
+func resolveQuestionType(name string) (string, bool) {
+	want := strings.ReplaceAll(name, " ", "")
+	for _, id := range questionTypeIDs() {
+		if strings.ReplaceAll(id, " ", "") == want {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// difficultyNames lists the difficulty levels with their English aliases.
+func difficultyNames() []string {
+	var names []string
+	for _, lvl := range difficultyLevels {
+		names = append(names, fmt.Sprintf("%s (%s)", lvl.ID, lvl.Alias))
+	}
+	return names
+}
+
+// resolveLearner checks the -difficulty and -grade flags. A grade alone picks
+// the level closest to it.
+func resolveLearner(difficulty string, grade int) (LearnerProfile, error) {
+	if grade != 0 {
+		if _, ok := findGrade(grade); !ok {
+			return LearnerProfile{}, fmt.Errorf("-grade must be between %d and %d", learnerGrades[0].Grade, learnerGrades[len(learnerGrades)-1].Grade)
+		}
+	}
+	if difficulty == "" {
+		if grade == 0 {
+			return LearnerProfile{}, nil
+		}
+		return LearnerProfile{Difficulty: difficultyForGrade(grade).ID, Grade: grade}, nil
+	}
+	lvl, ok := findDifficulty(difficulty)
+	if !ok {
+		return LearnerProfile{}, fmt.Errorf("unknown difficulty %q (available: %s)", difficulty, strings.Join(difficultyNames(), ", "))
+	}
+	return LearnerProfile{Difficulty: lvl.ID, Grade: grade}, nil
+}
+
+func firstPositive(values ...int) int {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cliTestPaper = "1. 다음 빈칸에 들어갈 말로 가장 적절한 것은?\nHe sat on the _______ of the river.\n① bank\n② run\n③ fair\n④ bear\n⑤ light\n\n[정답]\n1. ①\n"
+
+func TestRunGenerateCLI(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	one := write("one.txt", "bank = 둑\n")
+	list := write("list.txt", "bank = 은행\nrun = 달리다\nfair = 공정한\nbear = 참다\nlight = 가벼운\n")
+	config := write("api.json", `{"chatgpt_api_key": "test", "max_regenerations": 0, "templates_dir": "`+filepath.Join(dir, "none")+`"}`)
+	noConfig := filepath.Join(dir, "missing.json")
+
+	tests := []struct {
+		name   string
+		args   []string
+		output string // what the fake model returns
+		genErr error
+		code   int
+		stdout string // substring of stdout
+		stderr string // substring of stderr
+		calls  int
+	}{
+		{name: "missing input", args: []string{"-config", config}, code: exitUsage, stderr: "-i is required"},
+		{name: "unknown flag", args: []string{"-i", one, "-bogus"}, code: exitUsage, stderr: "flag provided but not defined: -bogus"},
+		{name: "unknown type", args: []string{"-i", one, "-config", config, "-type", "없는 유형"}, code: exitUsage, stderr: `unknown question type "없는 유형"`},
+		{name: "bad mix", args: []string{"-i", one, "-config", config, "-mix", "빈칸 추론 50, 없는 유형 50"}, code: exitUsage, stderr: "error: -mix:"},
+		{name: "forms out of range", args: []string{"-i", one, "-config", config, "-forms", "0"}, code: exitUsage, stderr: "-forms must be between 1 and"},
+		{name: "unknown difficulty", args: []string{"-i", one, "-config", config, "-difficulty", "최상"}, code: exitUsage},
+		{name: "model type offline", args: []string{"-i", one, "-config", config, "-provider", "offline"}, code: exitUsage, stderr: "needs a model"},
+		{name: "input not found", args: []string{"-i", filepath.Join(dir, "nope.txt"), "-config", config}, code: exitError, stderr: "error: reading input"},
+		{name: "no config", args: []string{"-i", one, "-config", noConfig}, code: exitError, stderr: "error: reading " + noConfig},
+		{name: "empty list", args: []string{"-i", write("empty.txt", "# nothing\n"), "-config", config}, code: exitError, stderr: "no vocabulary entries found"},
+		{name: "generated", args: []string{"-i", one, "-config", config, "-q", "-seed", "1"}, output: cliTestPaper, code: exitOK, stdout: "[정답]\n1. ", calls: 1},
+		{name: "validation issues", args: []string{"-i", one, "-config", config, "-q"}, output: strings.Replace(cliTestPaper, "① bank", "① banks", 1), code: exitIssues, stdout: "① banks", stderr: "warning: ", calls: 1},
+		{name: "model error", args: []string{"-i", one, "-config", config, "-q"}, genErr: errors.New("boom"), code: exitError, stderr: "error: boom", calls: 1},
+		{name: "offline type needs no config", args: []string{"-i", list, "-config", noConfig, "-type", "철자 배열", "-q"}, code: exitOK, stdout: "철자를 바르게 배열하여"},
+		{name: "offline mix", args: []string{"-i", list, "-config", noConfig, "-mix", "철자 배열 50, 첫 글자 힌트 50", "-q"}, code: exitOK, stdout: "첫 글자로 시작하는"},
+		{name: "mix with a model type", args: []string{"-i", one, "-config", noConfig, "-mix", "철자 배열 50, 빈칸 추론 50"}, code: exitError, stderr: "error: reading " + noConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			saved := cliGenerator
+			defer func() { cliGenerator = saved }()
+			cliGenerator = func(provider string, cfg APIConfig) (Generator, error) {
+				return generatorFunc(func(ctx context.Context, req GenerateRequest) (string, error) {
+					calls++
+					if req.Model != defaultModels[providerOpenAI] {
+						t.Errorf("model %q", req.Model)
+					}
+					return tt.output, tt.genErr
+				}), nil
+			}
+
+			var stdout, stderr bytes.Buffer
+			code := runGenerateCLI(tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("exit code %d, want %d; stderr:\n%s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("stdout:\n%s\nwant it to contain %q", stdout.String(), tt.stdout)
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("stderr:\n%s\nwant it to contain %q", stderr.String(), tt.stderr)
+			}
+			if calls != tt.calls {
+				t.Errorf("%d model requests, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRunCLI(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{nil, exitUsage},
+		{[]string{"help"}, exitOK},
+		{[]string{"serve"}, exitUsage},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := runCLI(tt.args, &stdout, &stderr); code != tt.code {
+			t.Errorf("runCLI(%q) = %d, want %d", tt.args, code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DifficultyLevel is one entry of the difficulty menu. Its rules replace the
+// "intentionally challenging" goal of the style block.
+type DifficultyLevel struct {
+	ID          string // menu name, e.g. "중급"
+	Alias       string // English name accepted on the command line
+	Description string
+	// Grade is the target grade offered first when the level is chosen.
+	Grade int
+	// Sentences, Distractors and Vocabulary become the level rules of the prompt.
+	Sentences, Distractors, Vocabulary string
+}
+
+var difficultyLevels = []DifficultyLevel{
+	{
+		ID: "초급", Alias: "beginner", Description: "짧고 쉬운 문장, 뜻이 뚜렷이 다른 오답", Grade: 7,
+		Sentences:   "Use short, simple sentences of about 6–12 words on everyday topics (school, family, hobbies) with basic grammar: present and past tenses, no relative clauses or participle phrases.",
+		Distractors: "Make the distractors clearly different in meaning from the answer, so that a student who knows the WORD finds the answer without hesitation.",
+		Vocabulary:  "Apart from the WORDs, use only basic vocabulary (roughly the 1,000 most common English words); every choice must be a word the students are likely to know.",
+	},
+	{
+		ID: "중급", Alias: "intermediate", Description: "교과서 수준 문장, 그럴듯하지만 문맥에 맞지 않는 오답", Grade: 9,
+		Sentences:   "Use sentences of about 10–18 words on familiar topics, with common compound and complex structures (because/when/if clauses, simple relative clauses).",
+		Distractors: "Make the distractors plausible—same part of speech, sometimes related in topic—but only one choice may fit the context.",
+		Vocabulary:  "Keep the other words within the middle school curriculum (roughly 2,000 words).",
+	},
+	{
+		ID: "고급", Alias: "advanced", Description: "긴 문장과 추상적 주제, 의미가 가까운 오답", Grade: 11,
+		Sentences:   "Use sentences of about 15–25 words, including abstract topics (society, science, culture) and structures such as relative clauses, participle phrases and passives.",
+		Distractors: "Make the distractors close in meaning or form to the answer; use other senses of the same WORD as traps where possible.",
+		Vocabulary:  "Keep the other words within the high school textbook range (roughly 3,000–4,000 words).",
+	},
+	{
+		ID: "수능", Alias: "csat", Description: "수능 지문 수준, 일부러 헷갈리게 만든 오답", Grade: 12,
+		Sentences:   "Write sentences at the level of CSAT reading passages: 20–30 words, academic topics (psychology, economics, science, philosophy) and complex structures.",
+		Distractors: "The questions should be intentionally challenging, designed to confuse the test-taker and test their ability to discern the correct meaning from context.",
+		Vocabulary:  "Use the full CSAT vocabulary range (5,000 words and more) in the sentences and choices.",
+	},
+}
+
+// LearnerGrade is one school year the questions can be aimed at.
+type LearnerGrade struct {
+	Grade int    // 7–12
+	Title string // "중1"
+	Year  string // English name used in the prompt
+	CEFR  string
+}
+
+var learnerGrades = []LearnerGrade{
+	{7, "중1", "1st-year middle school", "A1"},
+	{8, "중2", "2nd-year middle school", "A1–A2"},
+	{9, "중3", "3rd-year middle school", "A2"},
+	{10, "고1", "1st-year high school", "A2–B1"},
+	{11, "고2", "2nd-year high school", "B1–B2"},
+	{12, "고3", "3rd-year high school", "B2"},
+}
+
+// LearnerProfile is the difficulty and target grade of a job. The zero value
+// keeps the original challenging style.
+type LearnerProfile struct {
+	Difficulty string // a DifficultyLevel ID, or "" for none
+	Grade      int    // 0 uses the level's grade
+}
+
+// describe returns the learner description and level rules for the prompt, or
+// ok false if no difficulty was chosen.
+func (p LearnerProfile) describe() (learner string, rules []string, ok bool) {
+	lvl, ok := findDifficulty(p.Difficulty)
+	if !ok {
+		return "", nil, false
+	}
+	grade := p.Grade
+	if grade == 0 {
+		grade = lvl.Grade
+	}
+	learner = "Korean students"
+	if g, found := findGrade(grade); found {
+		learner = fmt.Sprintf("Korean %s students (grade %d, CEFR %s)", g.Year, g.Grade, g.CEFR)
+	}
+	rules = []string{
+		"Sentences: " + lvl.Sentences,
+		"Distractors: " + lvl.Distractors,
+		"Vocabulary: " + lvl.Vocabulary,
+	}
+	return learner, rules, true
+}
+
+// findDifficulty looks up a level by its menu name or English alias.
+func findDifficulty(name string) (DifficultyLevel, bool) {
+	name = strings.TrimSpace(name)
+	for _, lvl := range difficultyLevels {
+		if lvl.ID == name || strings.EqualFold(lvl.Alias, name) {
+			return lvl, true
+		}
+	}
+	return DifficultyLevel{}, false
+}
+
+func findGrade(grade int) (LearnerGrade, bool) {
+	for _, g := range learnerGrades {
+		if g.Grade == grade {
+			return g, true
+		}
+	}
+	return LearnerGrade{}, false
+}
+
+// difficultyForGrade picks the level whose default grade is closest to grade,
+// for a grade given without a difficulty.
+func difficultyForGrade(grade int) DifficultyLevel {
+	best := difficultyLevels[0]
+	for _, lvl := range difficultyLevels[1:] {
+		if abs(lvl.Grade-grade) < abs(best.Grade-grade) {
+			best = lvl
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveLearner(t *testing.T) {
+	tests := []struct {
+		difficulty string
+		grade      int
+		want       LearnerProfile
+		wantErr    string
+	}{
+		{"", 0, LearnerProfile{}, ""},
+		{"중급", 0, LearnerProfile{Difficulty: "중급"}, ""},
+		{"Intermediate", 0, LearnerProfile{Difficulty: "중급"}, ""},
+		{" csat ", 10, LearnerProfile{Difficulty: "수능", Grade: 10}, ""},
+		{"", 7, LearnerProfile{Difficulty: "초급", Grade: 7}, ""},
+		{"", 8, LearnerProfile{Difficulty: "초급", Grade: 8}, ""}, // a tie goes to the easier level
+		{"", 11, LearnerProfile{Difficulty: "고급", Grade: 11}, ""},
+		{"", 12, LearnerProfile{Difficulty: "수능", Grade: 12}, ""},
+		{"", 6, LearnerProfile{}, "-grade must be between 7 and 12"},
+		{"고급", 13, LearnerProfile{}, "-grade must be between 7 and 12"},
+		{"최상", 0, LearnerProfile{}, `unknown difficulty "최상" (available: 초급 (beginner), 중급 (intermediate), 고급 (advanced), 수능 (csat))`},
+	}
+	for _, tt := range tests {
+		got, err := resolveLearner(tt.difficulty, tt.grade)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("resolveLearner(%q, %d): unexpected error: %v", tt.difficulty, tt.grade, err)
+		case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+			t.Errorf("resolveLearner(%q, %d): error = %v, want %q", tt.difficulty, tt.grade, err, tt.wantErr)
+		case got != tt.want:
+			t.Errorf("resolveLearner(%q, %d) = %+v, want %+v", tt.difficulty, tt.grade, got, tt.want)
+		}
+	}
+}
+
+func TestLearnerProfileDescribe(t *testing.T) {
+	beginner, _ := findDifficulty("초급")
+	tests := []struct {
+		profile LearnerProfile
+		learner string
+		ok      bool
+	}{
+		{LearnerProfile{}, "", false},
+		{LearnerProfile{Difficulty: "없음"}, "", false},
+		{LearnerProfile{Difficulty: "초급"}, "Korean 1st-year middle school students (grade 7, CEFR A1)", true},
+		{LearnerProfile{Difficulty: "초급", Grade: 11}, "Korean 2nd-year high school students (grade 11, CEFR B1–B2)", true},
+	}
+	for _, tt := range tests {
+		learner, rules, ok := tt.profile.describe()
+		if learner != tt.learner || ok != tt.ok {
+			t.Errorf("%+v: describe() = %q, %v, want %q, %v", tt.profile, learner, ok, tt.learner, tt.ok)
+		}
+		var want []string
+		if ok {
+			want = []string{"Sentences: " + beginner.Sentences, "Distractors: " + beginner.Distractors, "Vocabulary: " + beginner.Vocabulary}
+		}
+		if !reflect.DeepEqual(rules, want) {
+			t.Errorf("%+v: rules = %q", tt.profile, rules)
+		}
+	}
+}
+
+func TestBuildPromptsDifficulty(t *testing.T) {
+	beginner, _ := findDifficulty("초급")
+	vocab := []VocabPair{{Word: "bank", Meanings: []string{"은행"}}}
+	tests := []struct {
+		name     string
+		learner  LearnerProfile
+		contains []string
+		missing  []string
+	}{
+		{
+			name:     "no level",
+			contains: []string{"GOAL: The questions should be intentionally challenging"},
+			missing:  []string{"LEVEL:"},
+		},
+		{
+			name:    "level and grade",
+			learner: LearnerProfile{Difficulty: "초급", Grade: 8},
+			contains: []string{
+				"LEVEL: The test-takers are Korean 2nd-year middle school students (grade 8, CEFR A1–A2). Match every question to the '초급' level:",
+				"   - Sentences: " + beginner.Sentences,
+				"   - Distractors: " + beginner.Distractors,
+				"   - Vocabulary: " + beginner.Vocabulary,
+			},
+			missing: []string{"intentionally challenging"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, qt := range []string{"빈칸 추론", "영영풀이"} {
+				system, _, err := buildPrompts(vocab, qt, 2, tt.learner, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, s := range tt.contains {
+					if !strings.Contains(system, s) {
+						t.Errorf("%s: system prompt lacks %q", qt, s)
+					}
+				}
+				for _, s := range tt.missing {
+					if strings.Contains(system, s) {
+						t.Errorf("%s: system prompt has %q", qt, s)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrAuth
+	ErrRateLimit
+	ErrQuota
+	ErrContextLength
+	ErrTransient
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrAuth:
+		return "인증 오류"
+	case ErrRateLimit:
+		return "요청 한도 초과"
+	case ErrQuota:
+		return "사용량/결제 한도 초과"
+	case ErrContextLength:
+		return "입력이 너무 김"
+	case ErrTransient:
+		return "일시적 오류"
+	}
+	return "API 오류"
+}
+
+// GenerationError is the typed error every Generator returns for HTTP and network failures.
+type GenerationError struct {
+	Kind       ErrorKind
+	StatusCode int
+	Message    string
+	// RetryAfter is the wait the server asked for, taken from Retry-After or the rate limit headers.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *GenerationError) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s (%d): %s", e.Kind, e.StatusCode, msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, msg)
+}
+
+func (e *GenerationError) Unwrap() error { return e.Err }
+
+// Retryable reports whether sending the same request again may succeed.
+func (e *GenerationError) Retryable() bool {
+	return e.Kind == ErrRateLimit || e.Kind == ErrTransient
+}
+
+// errorBody matches the error envelope shared by OpenAI, Anthropic and Gemini:
+// {"error": {"message": ..., "type"/"status": ..., "code": ...}}.
+type errorBody struct {
+	Error struct {
+		Message string          `json:"message"`
+		Type    string          `json:"type"`
+		Status  string          `json:"status"`
+		Code    json.RawMessage `json:"code"`
+	} `json:"error"`
+}
+
+// newHTTPError classifies a non-2xx response into a GenerationError.
+func newHTTPError(resp *http.Response, body []byte) *GenerationError {
+	e := &GenerationError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfterFromHeaders(resp.Header),
+	}
+
+	var eb errorBody
+	var code string
+	if json.Unmarshal(body, &eb) == nil && eb.Error.Message != "" {
+		e.Message = eb.Error.Message
+		code = strings.Trim(string(eb.Error.Code), `"`)
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+		if len(e.Message) > 200 {
+			e.Message = e.Message[:200] + "..."
+		}
+	}
+	errType := strings.ToLower(eb.Error.Type + " " + eb.Error.Status + " " + code)
+	lowerMsg := strings.ToLower(e.Message)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		e.Kind = ErrAuth
+	case strings.Contains(errType, "insufficient_quota") || strings.Contains(lowerMsg, "billing") || strings.Contains(lowerMsg, "credit balance"):
+		e.Kind = ErrQuota
+	case strings.Contains(errType, "context_length") || strings.Contains(lowerMsg, "context length") ||
+		strings.Contains(lowerMsg, "maximum context") || strings.Contains(lowerMsg, "prompt is too long"):
+		e.Kind = ErrContextLength
+	case resp.StatusCode == http.StatusTooManyRequests:
+		e.Kind = ErrRateLimit
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= 500:
+		// Includes Anthropic's 529 "overloaded_error".
+		e.Kind = ErrTransient
+	default:
+		e.Kind = ErrUnknown
+	}
+	return e
+}
+
+// newNetworkError wraps a failure that happened before or while reading the response.
+// Cancellation is passed through untouched so callers can tell it apart from real failures.
+func newNetworkError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	return &GenerationError{Kind: ErrTransient, Message: err.Error(), Err: err}
+}
+
+// retryAfterFromHeaders reads how long the server wants us to wait. Retry-After
+// (seconds or HTTP date) wins; otherwise the reset time of an exhausted
+// x-ratelimit-* / anthropic-ratelimit-* bucket is used.
+func retryAfterFromHeaders(h http.Header) time.Duration {
+	if v := h.Get("retry-after-ms"); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(secs * float64(time.Second))
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return time.Until(t)
+		}
+	}
+
+	var wait time.Duration
+	for _, bucket := range []string{"requests", "tokens"} {
+		for _, prefix := range []string{"x-ratelimit-", "anthropic-ratelimit-"} {
+			remaining := h.Get(prefix + "remaining-" + bucket)
+			if remaining != "0" {
+				continue
+			}
+			if d := parseResetHeader(h.Get(prefix + "reset-" + bucket)); d > wait {
+				wait = d
+			}
+		}
+	}
+	return wait
+}
+
+// parseResetHeader accepts OpenAI's durations ("6m0s", "20ms") and Anthropic's RFC 3339 timestamps.
+func parseResetHeader(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return d
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testResponse serves one canned response and returns it as a client sees it.
+func testResponse(t *testing.T, status int, header map[string]string, body string) (*http.Response, []byte) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, data
+}
+
+func TestNewHTTPError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		kind      ErrorKind
+		message   string
+		retryable bool
+	}{
+		{"openai quota", 429, `{"error": {"message": "You exceeded your current quota", "type": "insufficient_quota", "code": "insufficient_quota"}}`, ErrQuota, "You exceeded your current quota", false},
+		{"openai rate limit", 429, `{"error": {"message": "Rate limit reached for requests", "type": "requests", "code": "rate_limit_exceeded"}}`, ErrRateLimit, "Rate limit reached for requests", true},
+		{"openai bad key", 401, `{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error", "code": "invalid_api_key"}}`, ErrAuth, "Incorrect API key provided", false},
+		{"openai context length", 400, `{"error": {"message": "This model's maximum context length is 8192 tokens", "type": "invalid_request_error", "code": "context_length_exceeded"}}`, ErrContextLength, "This model's maximum context length is 8192 tokens", false},
+		{"openai server error", 500, `{"error": {"message": "The server had an error", "type": "server_error", "code": null}}`, ErrTransient, "The server had an error", true},
+		{"anthropic rate limit", 429, `{"type": "error", "error": {"type": "rate_limit_error", "message": "Number of requests has exceeded your rate limit"}}`, ErrRateLimit, "Number of requests has exceeded your rate limit", true},
+		{"anthropic overloaded", 529, `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`, ErrTransient, "Overloaded", true},
+		{"anthropic credit", 400, `{"type": "error", "error": {"type": "invalid_request_error", "message": "Your credit balance is too low to access the Anthropic API"}}`, ErrQuota, "Your credit balance is too low to access the Anthropic API", false},
+		{"anthropic bad key", 401, `{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`, ErrAuth, "invalid x-api-key", false},
+		{"anthropic prompt too long", 400, `{"type": "error", "error": {"type": "invalid_request_error", "message": "prompt is too long: 210000 tokens > 200000 maximum"}}`, ErrContextLength, "prompt is too long: 210000 tokens > 200000 maximum", false},
+		{"gemini exhausted", 429, `{"error": {"code": 429, "message": "Resource has been exhausted (e.g. check quota).", "status": "RESOURCE_EXHAUSTED"}}`, ErrRateLimit, "Resource has been exhausted (e.g. check quota).", true},
+		{"gemini permission", 403, `{"error": {"code": 403, "message": "Method doesn't allow unregistered callers.", "status": "PERMISSION_DENIED"}}`, ErrAuth, "Method doesn't allow unregistered callers.", false},
+		{"gemini unavailable", 503, `{"error": {"code": 503, "message": "The model is overloaded. Please try again later.", "status": "UNAVAILABLE"}}`, ErrTransient, "The model is overloaded. Please try again later.", true},
+		{"plain text gateway error", 502, "Bad Gateway\n", ErrTransient, "Bad Gateway", true},
+		{"timeout", 408, "", ErrTransient, "", true},
+		{"unknown", 404, `{"error": {"message": "The model does not exist"}}`, ErrUnknown, "The model does not exist", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := testResponse(t, tt.status, nil, tt.body)
+			e := newHTTPError(resp, body)
+			if e.Kind != tt.kind || e.StatusCode != tt.status || e.Message != tt.message {
+				t.Errorf("got %v %d %q, want %v %d %q", e.Kind, e.StatusCode, e.Message, tt.kind, tt.status, tt.message)
+			}
+			if e.Retryable() != tt.retryable {
+				t.Errorf("Retryable = %v, want %v", e.Retryable(), tt.retryable)
+			}
+		})
+	}
+}
+
+func TestNewHTTPErrorTruncatesLongBodies(t *testing.T) {
+	long := make([]byte, 300)
+	for i := range long {
+		long[i] = 'x'
+	}
+	resp, body := testResponse(t, 500, nil, string(long))
+	if e := newHTTPError(resp, body); len(e.Message) != 203 {
+		t.Errorf("message of %d bytes, want 200 and \"...\"", len(e.Message))
+	}
+}
+
+func TestRetryAfterFromHeaders(t *testing.T) {
+	// Dates have whole seconds, so waits until a date may come out up to a second short.
+	inAMinute := time.Now().Add(time.Minute)
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   time.Duration
+		slack  time.Duration
+	}{
+		{"none", nil, 0, 0},
+		{"seconds", map[string]string{"Retry-After": "12"}, 12 * time.Second, 0},
+		{"fractional seconds", map[string]string{"Retry-After": "1.5"}, 1500 * time.Millisecond, 0},
+		{"milliseconds", map[string]string{"retry-after-ms": "250", "Retry-After": "1"}, 250 * time.Millisecond, 0},
+		{"http date", map[string]string{"Retry-After": inAMinute.UTC().Format(http.TimeFormat)}, time.Minute, 2 * time.Second},
+		{"garbage", map[string]string{"Retry-After": "soon"}, 0, 0},
+		{"openai requests exhausted", map[string]string{"x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "6m0s"}, 6 * time.Minute, 0},
+		{
+			name: "only exhausted buckets count",
+			header: map[string]string{
+				"x-ratelimit-remaining-requests": "5", "x-ratelimit-reset-requests": "1m",
+				"x-ratelimit-remaining-tokens": "0", "x-ratelimit-reset-tokens": "20ms",
+			},
+			want: 20 * time.Millisecond,
+		},
+		{
+			name: "longest exhausted bucket",
+			header: map[string]string{
+				"x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "2s",
+				"x-ratelimit-remaining-tokens": "0", "x-ratelimit-reset-tokens": "7s",
+			},
+			want: 7 * time.Second,
+		},
+		{"anthropic timestamp", map[string]string{"anthropic-ratelimit-remaining-tokens": "0", "anthropic-ratelimit-reset-tokens": inAMinute.UTC().Format(time.RFC3339)}, time.Minute, 2 * time.Second},
+		{"retry-after wins", map[string]string{"Retry-After": "3", "x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "1m"}, 3 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := testResponse(t, http.StatusTooManyRequests, tt.header, "")
+			got := retryAfterFromHeaders(resp.Header)
+			if got > tt.want || got < tt.want-tt.slack {
+				t.Errorf("got %v, want %v (-%v)", got, tt.want, tt.slack)
+			}
+			if e := newHTTPError(resp, nil); e.RetryAfter > tt.want || e.RetryAfter < tt.want-tt.slack {
+				t.Errorf("GenerationError.RetryAfter = %v, want %v", e.RetryAfter, tt.want)
+			}
+		})
+	}
+}
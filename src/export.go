@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// formHeaderRe matches the line formatForms puts above each form.
+var formHeaderRe = regexp.MustCompile(`(?m)^=+ ([A-Z])형 =+\s*$`)
+
+func formHeader(name string) string {
+	return fmt.Sprintf("========== %s형 ==========", name)
+}
+
+// answerKeyPath is the file the answer key of the paper saved at path goes to,
+// e.g. "unit3_problem.txt" -> "unit3_problem_answers.txt".
+func answerKeyPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_answers" + ext
+}
+
+// splitPaper separates a paper into the student version and the answer key.
+// It works on the text, so edits made in the output pane are kept. Each form
+// of a multi-form paper keeps its header in both parts; the cross-reference
+// table after the last key goes with the answers. key is empty if the text has
+// no [정답] section.
+func splitPaper(text string) (paper, key string) {
+	locs := formHeaderRe.FindAllStringIndex(text, -1)
+	if len(locs) == 0 {
+		return splitSection(text)
+	}
+	var papers, keys []string
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		header := strings.TrimSpace(text[loc[0]:loc[1]])
+		p, k := splitSection(text[loc[1]:end])
+		papers = append(papers, header+"\n\n"+p)
+		if k != "" {
+			keys = append(keys, header+"\n\n"+k)
+		}
+	}
+	return strings.Join(papers, "\n"), strings.Join(keys, "\n")
+}
+
+// splitSection splits one paper at its last [정답] header.
+func splitSection(text string) (paper, key string) {
+	locs := answerHeaderRe.FindAllStringIndex(text, -1)
+	if len(locs) == 0 {
+		return strings.TrimSpace(text) + "\n", ""
+	}
+	last := locs[len(locs)-1]
+	return strings.TrimSpace(text[:last[0]]) + "\n", strings.TrimSpace(text[last[0]:]) + "\n"
+}
+
+// explainQuestions returns copies of questions whose answers carry the tested
+// word and its meaning as explanation, e.g. "1. ③ (bank: 둑)". Questions that
+// already have an explanation, or test several words, are left as they are.
+func explainQuestions(questions []Question, vocab []VocabPair) []Question {
+	out := make([]Question, len(questions))
+	for i, q := range questions {
+		if q.Explanation == "" && q.Word != "" {
+			sense := q.Sense
+			if v, ok := findVocab(vocab, q.Word, q.POS); ok && sense == "" {
+				sense = strings.Join(v.Meanings, ", ")
+			}
+			switch {
+			case sense == "":
+			case strings.EqualFold(q.AnswerText, q.Word):
+				// A written answer already is the word.
+				q.Explanation = sense
+			default:
+				q.Explanation = fmt.Sprintf("%s: %s", q.Word, sense)
+			}
+		}
+		out[i] = q
+	}
+	return out
+}
+
+// formatExplainedKey renders the answer key of the paper, or of every form and
+// the cross-reference table, with explanations.
+func formatExplainedKey(questions []Question, forms []TestForm, vocab []VocabPair) string {
+	if len(forms) == 0 {
+		return formatAnswerKey(explainQuestions(questions, vocab))
+	}
+	var parts []string
+	for _, f := range forms {
+		parts = append(parts, formHeader(f.Name)+"\n\n"+formatAnswerKey(explainQuestions(f.Questions, vocab)))
+	}
+	parts = append(parts, formatFormTable(forms))
+	return strings.Join(parts, "\n")
+}
+
+// paperAndKey splits text into the student paper and the answer key. With
+// explain, the key is rebuilt from the generated questions with explanations,
+// as long as text is still the paper of those questions or forms; explained
+// reports whether it was. A paper edited by hand keeps its own key, since the
+// questions no longer tell which answer goes with which edited question.
+func paperAndKey(text string, questions []Question, forms []TestForm, vocab []VocabPair, explain bool) (paper, key string, explained bool) {
+	paper, key = splitPaper(text)
+	if !explain || len(questions) == 0 {
+		return paper, key, false
+	}
+	generated := formatQuestions(questions)
+	if len(forms) > 0 {
+		generated = formatForms(forms)
+	}
+	if strings.TrimSpace(text) != strings.TrimSpace(generated) {
+		return paper, key, false
+	}
+	return paper, formatExplainedKey(questions, forms, vocab), true
+}
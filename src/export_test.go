@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitPaper(t *testing.T) {
+	questions := testPaper(3)
+	forms := makeForms(questions, 2, nil, 1)
+	tests := []struct {
+		name       string
+		text       string
+		paper, key string
+		sections   [][]Question // questions of each section of the paper
+	}{
+		{
+			name:     "single paper",
+			text:     formatQuestions(questions),
+			paper:    strings.Join([]string{formatQuestion(questions[0]), formatQuestion(questions[1]), formatQuestion(questions[2])}, "\n---\n") + "\n",
+			key:      formatAnswerKey(questions),
+			sections: [][]Question{questions},
+		},
+		{
+			name: "forms",
+			text: formatForms(forms),
+			paper: formHeader("A") + "\n\n" + strings.TrimSuffix(formatQuestions(forms[0].Questions), "\n\n"+formatAnswerKey(forms[0].Questions)) + "\n" +
+				"\n" + formHeader("B") + "\n\n" + strings.TrimSuffix(formatQuestions(forms[1].Questions), "\n\n"+formatAnswerKey(forms[1].Questions)) + "\n",
+			key: formHeader("A") + "\n\n" + formatAnswerKey(forms[0].Questions) +
+				"\n" + formHeader("B") + "\n\n" + formatAnswerKey(forms[1].Questions) + "\n" + formatFormTable(forms),
+			sections: [][]Question{forms[0].Questions, forms[1].Questions},
+		},
+		{
+			name:     "no key",
+			text:     formatQuestion(questions[0]) + "\n\n",
+			paper:    formatQuestion(questions[0]) + "\n",
+			sections: [][]Question{questions[:1]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paper, key := splitPaper(tt.text)
+			if paper != tt.paper {
+				t.Errorf("paper =\n%s\nwant\n%s", paper, tt.paper)
+			}
+			if key != tt.key {
+				t.Errorf("key =\n%s\nwant\n%s", key, tt.key)
+			}
+			// The paper prints as the questions it was made of.
+			sections := printSections(paper)
+			if len(sections) != len(tt.sections) {
+				t.Fatalf("%d sections, want %d", len(sections), len(tt.sections))
+			}
+			for i, s := range sections {
+				if len(s.Questions) != len(tt.sections[i]) {
+					t.Fatalf("section %d: %d questions, want %d", i, len(s.Questions), len(tt.sections[i]))
+				}
+				for j, q := range s.Questions {
+					if want := formatQuestion(tt.sections[i][j]); formatQuestion(q.Question) != want {
+						t.Errorf("section %d question %d prints as\n%s\nwant\n%s", i, j, formatQuestion(q.Question), want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPaperAndKey(t *testing.T) {
+	vocab := []VocabPair{
+		{Word: "word0", Meanings: []string{"뜻0"}},
+		{Word: "word1", Meanings: []string{"뜻1"}},
+		{Word: "word2", Meanings: []string{"뜻2"}},
+	}
+	questions := testPaper(3)
+	forms := makeForms(questions, 2, nil, 1)
+	explainedKey := "[정답]\n1. ① (word0: 뜻0)\n2. ① (word1: 뜻1)\n3. ① (word2: 뜻2)\n"
+	tests := []struct {
+		name      string
+		text      string
+		forms     []TestForm
+		explain   bool
+		key       string
+		explained bool
+	}{
+		{"plain key", formatQuestions(questions), nil, false, formatAnswerKey(questions), false},
+		{"explained", formatQuestions(questions), nil, true, explainedKey, true},
+		{"explained forms", formatForms(forms), forms, true, formatExplainedKey(questions, forms, vocab), true},
+		{
+			"edited paper keeps its key",
+			strings.Replace(formatQuestions(questions), "answer1", "edited", 1), nil, true,
+			formatAnswerKey(questions), false,
+		},
+		{"forms from an older paper", formatQuestions(questions), forms, true, formatAnswerKey(questions), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paper, key, explained := paperAndKey(tt.text, questions, tt.forms, vocab, tt.explain)
+			if wantPaper, _ := splitPaper(tt.text); paper != wantPaper {
+				t.Errorf("paper =\n%s\nwant\n%s", paper, wantPaper)
+			}
+			if key != tt.key || explained != tt.explained {
+				t.Errorf("key, explained =\n%s%v\nwant\n%s%v", key, explained, tt.key, tt.explained)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// maxForms is the number of form letters, A to Z.
+const maxForms = 26
+
+// tableHeader is the title line of the cross-reference table.
+const tableHeader = "[문항 대조표]"
+
+// TestForm is one variant of a paper. Source[i] is the index in the original
+// paper of the form's question i.
+type TestForm struct {
+	Name      string // "A", "B", ...
+	Questions []Question
+	Source    []int
+}
+
+// makeForms returns n forms of questions. Form A is the paper as generated; the
+// others shuffle the question order within each run of the same question type
+// and reshuffle the choices, with the answers balanced again per form.
+func makeForms(questions []Question, n int, weights []int, seed int64) []TestForm {
+	rng := rand.New(rand.NewSource(seed))
+	forms := make([]TestForm, n)
+	for k := range forms {
+		order := make([]int, len(questions))
+		for i := range order {
+			order[i] = i
+		}
+		if k > 0 {
+			shuffleSections(order, questions, rng)
+		}
+
+		qs := make([]Question, len(order))
+		for i, src := range order {
+			qs[i] = questions[src]
+			qs[i].Choices = append([]string(nil), questions[src].Choices...)
+		}
+		renumberQuestions(qs)
+		if k > 0 {
+			balanceAnswers(qs, weights, seed+int64(k))
+		}
+		forms[k] = TestForm{Name: string(rune('A' + k)), Questions: qs, Source: order}
+	}
+	return forms
+}
+
+// shuffleSections shuffles order within each run of questions of the same type,
+// so a mixed paper keeps its sections.
+func shuffleSections(order []int, questions []Question, rng *rand.Rand) {
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && questions[end].Type == questions[start].Type {
+			end++
+		}
+		section := order[start:end]
+		rng.Shuffle(len(section), func(i, j int) { section[i], section[j] = section[j], section[i] })
+		start = end
+	}
+}
+
+// formatForms renders every form with its own [정답] section, followed by the
+// cross-reference table.
+func formatForms(forms []TestForm) string {
+	var parts []string
+	for _, f := range forms {
+		parts = append(parts, formHeader(f.Name)+"\n\n"+formatQuestions(f.Questions))
+	}
+	parts = append(parts, formatFormTable(forms))
+	return strings.Join(parts, "\n")
+}
+
+// formatFormTable lists, for every question of form A, its number in each form
+// and the words it tests.
+func formatFormTable(forms []TestForm) string {
+	if len(forms) == 0 {
+		return ""
+	}
+	// number[k][src] is the number of original question src in form k.
+	number := make([][]int, len(forms))
+	for k, f := range forms {
+		number[k] = make([]int, len(f.Source))
+		for i, src := range f.Source {
+			number[k][src] = i + 1
+		}
+	}
+
+	var header []string
+	for _, f := range forms {
+		header = append(header, fmt.Sprintf("%-4s", f.Name))
+	}
+	lines := []string{tableHeader, strings.Join(header, " ") + " 단어"}
+	for src, q := range forms[0].Questions {
+		var cells []string
+		for k := range forms {
+			cells = append(cells, fmt.Sprintf("%-4d", number[k][src]))
+		}
+		words := q.Word
+		if len(q.Words) > 0 {
+			words = strings.Join(q.Words, ", ")
+		}
+		lines = append(lines, strings.Join(cells, " ")+" "+words)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
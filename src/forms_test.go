@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testPaper returns n questions whose correct choice names the question.
+func testPaper(n int, types ...string) []Question {
+	var questions []Question
+	for i := 0; i < n; i++ {
+		q := Question{
+			Number:  i + 1,
+			Prompt:  "다음 중 알맞은 것은?",
+			Choices: []string{fmt.Sprint("answer", i), "w1", "w2", "w3", "w4"},
+			Answer:  0,
+			Word:    fmt.Sprint("word", i),
+		}
+		if len(types) > 0 {
+			q.Type = types[i%len(types)]
+		}
+		questions = append(questions, q)
+	}
+	return questions
+}
+
+func TestMakeForms(t *testing.T) {
+	tests := []struct {
+		name      string
+		questions []Question
+		n         int
+	}{
+		{"two forms", testPaper(6), 2},
+		{"many forms", testPaper(10), 5},
+		{"sections", append(testPaper(4, "빈칸 추론"), testPaper(3, "영영풀이")...), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renumberQuestions(tt.questions)
+			original := fmt.Sprint(tt.questions)
+			forms := makeForms(tt.questions, tt.n, nil, 7)
+			if fmt.Sprint(tt.questions) != original {
+				t.Fatal("makeForms changed the original questions")
+			}
+			if len(forms) != tt.n {
+				t.Fatalf("got %d forms", len(forms))
+			}
+			if !reflect.DeepEqual(forms[0].Questions, tt.questions) {
+				t.Error("form A is not the paper as generated")
+			}
+			for k, f := range forms {
+				if f.Name != string(rune('A'+k)) {
+					t.Errorf("form %d is named %q", k, f.Name)
+				}
+				seen := make(map[int]bool)
+				for i, q := range f.Questions {
+					src := tt.questions[f.Source[i]]
+					if q.Number != i+1 {
+						t.Errorf("%s형 question %d is numbered %d", f.Name, i+1, q.Number)
+					}
+					if q.Type != src.Type {
+						t.Errorf("%s형 question %d moved out of its section", f.Name, i+1)
+					}
+					if q.Choices[q.Answer] != src.Choices[src.Answer] {
+						t.Errorf("%s형 question %d: answer %q, want %q", f.Name, i+1, q.Choices[q.Answer], src.Choices[src.Answer])
+					}
+					seen[f.Source[i]] = true
+				}
+				if len(seen) != len(tt.questions) {
+					t.Errorf("%s형 has %d of %d questions", f.Name, len(seen), len(tt.questions))
+				}
+			}
+			// The same seed makes the same forms.
+			if again := makeForms(tt.questions, tt.n, nil, 7); !reflect.DeepEqual(again, forms) {
+				t.Error("makeForms is not reproducible with the same seed")
+			}
+		})
+	}
+}
+
+func TestFormatFormTable(t *testing.T) {
+	questions := []Question{
+		{Number: 1, Word: "bank"},
+		{Number: 2, Words: []string{"conduct (n.)", "run"}},
+		{Number: 3, Word: "fair"},
+	}
+	forms := []TestForm{
+		{Name: "A", Questions: questions, Source: []int{0, 1, 2}},
+		{Name: "B", Questions: questions, Source: []int{2, 0, 1}},
+	}
+	want := "[문항 대조표]\n" +
+		"A    B    단어\n" +
+		"1    2    bank\n" +
+		"2    3    conduct (n.), run\n" +
+		"3    1    fair\n"
+	if got := formatFormTable(forms); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := formatFormTable(nil); got != "" {
+		t.Errorf("no forms gives %q", got)
+	}
+}
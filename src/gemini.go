@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta/models/"
+
+// Request structures
+type GeminiRequest struct {
+	SystemInstruction *GeminiContent         `json:"systemInstruction,omitempty"`
+	Contents          []GeminiContent        `json:"contents"`
+	GenerationConfig  GeminiGenerationConfig `json:"generationConfig"`
+}
+
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []GeminiPart `json:"parts"`
+}
+
+type GeminiPart struct {
+	Text string `json:"text"`
+}
+
+type GeminiGenerationConfig struct {
+	Temperature        float32        `json:"temperature"`
+	MaxOutputTokens    int            `json:"maxOutputTokens,omitempty"`
+	ResponseMimeType   string         `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema map[string]any `json:"responseJsonSchema,omitempty"`
+}
+
+// Response structures
+type GeminiResponse struct {
+	Candidates []GeminiCandidate `json:"candidates"`
+	Error      *GeminiError      `json:"error,omitempty"`
+}
+
+type GeminiCandidate struct {
+	Content      GeminiContent `json:"content"`
+	FinishReason string        `json:"finishReason"`
+}
+
+type GeminiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// geminiGenerator talks to the Gemini generateContent API.
+type geminiGenerator struct {
+	baseURL string
+	apiKey  string
+}
+
+func (g *geminiGenerator) Generate(ctx context.Context, genReq GenerateRequest) (string, error) {
+	reqBody := GeminiRequest{
+		SystemInstruction: &GeminiContent{Parts: []GeminiPart{{Text: genReq.SystemPrompt}}},
+		Contents: []GeminiContent{
+			{Role: "user", Parts: []GeminiPart{{Text: genReq.UserPrompt}}},
+		},
+		GenerationConfig: GeminiGenerationConfig{
+			Temperature:     1.0,
+			MaxOutputTokens: maxTokensOrDefault(genReq.MaxTokens),
+		},
+	}
+
+	if genReq.Schema != nil {
+		reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		reqBody.GenerationConfig.ResponseJSONSchema = genReq.Schema.Schema
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("request JSON 생성 오류: %w", err)
+	}
+
+	url := g.baseURL + genReq.Model + ":generateContent"
+	if genReq.OnDelta != nil {
+		url = g.baseURL + genReq.Model + ":streamGenerateContent?alt=sse"
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("HTTP 요청 생성 오류: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", g.apiKey)
+
+	client := &http.Client{Timeout: 130 * time.Second}
+	if genReq.OnDelta != nil {
+		client.Timeout = streamTimeout
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", newNetworkError(ctx, fmt.Errorf("Gemini API 요청 오류: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if genReq.OnDelta != nil && resp.StatusCode == http.StatusOK {
+		text, err := readGeminiStream(resp.Body, genReq.OnDelta)
+		if err != nil {
+			return text, newNetworkError(ctx, err)
+		}
+		return text, nil
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", newNetworkError(ctx, fmt.Errorf("응답 읽기 오류: %w", err))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newHTTPError(resp, respBody)
+	}
+
+	var genResp GeminiResponse
+	if err := json.Unmarshal(respBody, &genResp); err != nil {
+		return "", fmt.Errorf("응답 JSON 파싱 오류: %w. 응답: %s", err, string(respBody))
+	}
+
+	if genResp.Error != nil {
+		return "", fmt.Errorf("API 오류: %s (%s)", genResp.Error.Message, genResp.Error.Status)
+	}
+
+	if len(genResp.Candidates) == 0 {
+		return "", fmt.Errorf("API가 비어있는 응답을 반환했습니다")
+	}
+
+	var sb strings.Builder
+	for _, p := range genResp.Candidates[0].Content.Parts {
+		sb.WriteString(p.Text)
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("API가 비어있는 응답을 반환했습니다")
+	}
+
+	return sb.String(), nil
+}
+
+// readGeminiStream consumes a streamGenerateContent SSE stream. Each event is a
+// full GeminiResponse carrying only the newly generated parts; a stream whose
+// last event has no finishReason was cut off.
+func readGeminiStream(body io.Reader, onDelta func(string)) (string, error) {
+	var sb strings.Builder
+	done := false
+	err := readSSE(body, func(_, data string) error {
+		var chunk GeminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("스트림 JSON 파싱 오류: %w. 데이터: %s", err, data)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("API 오류: %s (%s)", chunk.Error.Message, chunk.Error.Status)
+		}
+		if len(chunk.Candidates) == 0 {
+			return nil
+		}
+		done = chunk.Candidates[0].FinishReason != ""
+		for _, p := range chunk.Candidates[0].Content.Parts {
+			if p.Text != "" {
+				sb.WriteString(p.Text)
+				onDelta(p.Text)
+			}
+		}
+		return nil
+	})
+	if err == nil && !done {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return sb.String(), fmt.Errorf("스트림 읽기 오류: %w", err)
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("API가 비어있는 응답을 반환했습니다")
+	}
+	return sb.String(), nil
+}
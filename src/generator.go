@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	providerOpenAI    = "openai"
+	providerAnthropic = "anthropic"
+	providerGemini    = "gemini"
+	providerLocal     = "local"
+)
+
+const (
+	defaultMaxTokens = 8192
+	defaultLocalURL  = "http://localhost:11434/v1"
+)
+
+// GenerateRequest is the provider-independent description of a single generation call.
+type GenerateRequest struct {
+	Model        string
+	SystemPrompt string
+	UserPrompt   string
+	MaxTokens    int
+
+	// Schema, when set, asks the provider for a JSON response matching it.
+	// Providers without native support get the schema appended to the system prompt.
+	Schema *JSONSchema
+
+	// OnDelta, when set, switches the request to streaming mode and receives
+	// each partial chunk of text as it arrives. Generate still returns the full text.
+	OnDelta func(delta string)
+
+	// OnRetry, when set, is told about every automatic retry before its wait starts.
+	OnRetry func(attempt, maxRetries int, wait time.Duration, err *GenerationError)
+}
+
+// Generator sends prompts to an LLM backend and returns the generated text.
+// Cancelling ctx aborts the underlying HTTP request.
+type Generator interface {
+	Generate(ctx context.Context, req GenerateRequest) (string, error)
+}
+
+// newGenerator returns the Generator for the given provider, configured from api.json,
+// with automatic retries of rate-limited and transient failures.
+func newGenerator(provider string, cfg APIConfig) (Generator, error) {
+	gen, err := newProviderGenerator(provider, cfg)
+	if err != nil {
+		return nil, err
+	}
+	maxRetries := defaultMaxRetries
+	if cfg.MaxRetries != nil {
+		maxRetries = *cfg.MaxRetries
+	}
+	return withRetry(gen, maxRetries), nil
+}
+
+func newProviderGenerator(provider string, cfg APIConfig) (Generator, error) {
+	switch provider {
+	case providerOpenAI:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("OpenAI API 키가 설정되지 않았습니다. api.json 파일을 확인하세요")
+		}
+		return &openAIGenerator{endpoint: openAIEndpoint, apiKey: cfg.APIKey}, nil
+	case providerAnthropic:
+		if cfg.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("Anthropic API 키가 설정되지 않았습니다. api.json 파일을 확인하세요")
+		}
+		return &anthropicGenerator{endpoint: anthropicEndpoint, apiKey: cfg.AnthropicAPIKey}, nil
+	case providerGemini:
+		if cfg.GeminiAPIKey == "" {
+			return nil, fmt.Errorf("Gemini API 키가 설정되지 않았습니다. api.json 파일을 확인하세요")
+		}
+		return &geminiGenerator{baseURL: geminiBaseURL, apiKey: cfg.GeminiAPIKey}, nil
+	case providerLocal:
+		return &openAIGenerator{endpoint: localEndpoint(cfg)}, nil
+	}
+	return nil, fmt.Errorf("알 수 없는 provider: %s", provider)
+}
+
+// localEndpoint builds the chat completions URL of an OpenAI-compatible local server (Ollama, llama.cpp).
+func localEndpoint(cfg APIConfig) string {
+	base := cfg.LocalBaseURL
+	if base == "" {
+		base = defaultLocalURL
+	}
+	return strings.TrimSuffix(base, "/") + "/chat/completions"
+}
+
+// availableProviders lists the providers that can be used with the current api.json.
+// The local server needs no key, so it is only offered once it has been configured.
+func availableProviders(cfg APIConfig) []string {
+	var providers []string
+	if cfg.APIKey != "" {
+		providers = append(providers, providerOpenAI)
+	}
+	if cfg.AnthropicAPIKey != "" {
+		providers = append(providers, providerAnthropic)
+	}
+	if cfg.GeminiAPIKey != "" {
+		providers = append(providers, providerGemini)
+	}
+	if cfg.LocalBaseURL != "" || len(cfg.LocalModels) > 0 {
+		providers = append(providers, providerLocal)
+	}
+	return providers
+}
+
+func maxTokensOrDefault(n int) int {
+	if n <= 0 {
+		return defaultMaxTokens
+	}
+	return n
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// vocabImporter reads one file format into vocabulary pairs. The diagnostics
+// report the rows of the file that were skipped.
+type vocabImporter struct {
+	name       string
+	extensions []string
+	// sniff reports whether content looks like this format regardless of extension.
+	sniff func(content string) bool
+	parse func(content string) ([]VocabPair, []VocabDiagnostic, error)
+}
+
+// importers is tried in order: an importer claiming the file's extension wins,
+// then the first one whose sniff matches. The plain "word = meaning" format is the fallback.
+var importers = []vocabImporter{
+	{name: "Anki", extensions: nil, sniff: sniffAnki, parse: parseAnki},
+	{name: "JSON", extensions: []string{".json"}, sniff: sniffJSON, parse: func(c string) ([]VocabPair, []VocabDiagnostic, error) {
+		pairs, err := parseVocabJSON(c)
+		return pairs, nil, err
+	}},
+	{name: "TSV", extensions: []string{".tsv", ".tab"}, sniff: sniffTSV, parse: func(c string) ([]VocabPair, []VocabDiagnostic, error) { return parseDelimited(c, '\t') }},
+	{name: "CSV", extensions: []string{".csv"}, sniff: sniffCSV, parse: func(c string) ([]VocabPair, []VocabDiagnostic, error) { return parseDelimited(c, ',') }},
+	{name: "Text", extensions: []string{".txt", ".text", ".md", ".list"}, sniff: nil, parse: func(c string) ([]VocabPair, []VocabDiagnostic, error) {
+		pairs, diags := parseVocabLines(c)
+		return pairs, diags, nil
+	}},
+}
+
+// importerExtensions lists every extension some importer claims, for the file picker.
+func importerExtensions() []string {
+	var exts []string
+	for _, imp := range importers {
+		exts = append(exts, imp.extensions...)
+	}
+	return exts
+}
+
+// findImporter picks the importer for a file. Anki exports are sniffed first
+// because they are saved as .txt.
+func findImporter(path, content string) vocabImporter {
+	ext := strings.ToLower(filepath.Ext(path))
+	if sniffAnki(content) {
+		return importers[0]
+	}
+	for _, imp := range importers {
+		for _, e := range imp.extensions {
+			if e == ext && (imp.name != "Text" || !looksTabular(content)) {
+				return imp
+			}
+		}
+	}
+	for _, imp := range importers {
+		if imp.sniff != nil && imp.sniff(content) {
+			return imp
+		}
+	}
+	return importers[len(importers)-1]
+}
+
+// looksTabular reports a .txt file that is really tab-separated and has no "=" lines.
+func looksTabular(content string) bool {
+	return sniffTSV(content) && !strings.Contains(content, "=")
+}
+
+// importVocabText converts a loaded file into the "word = meaning" text shown in
+// the input pane. Plain text files are returned unchanged so the teacher's own
+// formatting survives. skipped reports the rows of other formats that were
+// left out, by their line in the file; they are not in text.
+func importVocabText(path string, content []byte) (text, format string, count int, skipped []VocabDiagnostic, err error) {
+	s := strings.TrimPrefix(string(content), "\ufeff")
+	imp := findImporter(path, s)
+	if imp.name == "Text" {
+		return s, imp.name, len(parseVocabBlock(s)), nil, nil
+	}
+	pairs, skipped, err := imp.parse(s)
+	if err != nil {
+		return "", imp.name, 0, nil, fmt.Errorf("%s 파일 읽기 오류: %w", imp.name, err)
+	}
+	return formatVocabBlock(pairs), imp.name, len(pairs), skipped, nil
+}
+
+// importVocab reads a file of any supported format into pairs, with the skipped
+// rows of the file followed by the diagnostics of the converted text.
+func importVocab(path string, content []byte) ([]VocabPair, []VocabDiagnostic, error) {
+	text, _, _, skipped, err := importVocabText(path, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	pairs, diags := parseVocabLines(text)
+	return pairs, append(skipped, diags...), nil
+}
+
+// formatVocabBlock renders pairs in the "word = meaning1, meaning2" input syntax.
+func formatVocabBlock(pairs []VocabPair) string {
+	var lines []string
+	for _, p := range pairs {
+		lines = append(lines, formatVocabLine(p))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+var (
+	wordColumnNames    = []string{"word", "words", "term", "english", "front", "vocab", "vocabulary", "단어", "영단어", "영어"}
+	meaningColumnNames = []string{"meaning", "meanings", "definition", "korean", "back", "translation", "뜻", "의미", "한국어", "우리말"}
+)
+
+func columnIndex(header []string, names []string) int {
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		for _, n := range names {
+			if h == n {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitMeanings splits a meaning cell on ',' and ';' the same way parseVocabBlock does.
+func splitMeanings(cell string) []string {
+	var out []string
+	for _, s := range senseSplitRe.Split(cell, -1) {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// parseDelimited reads CSV/TSV. A header row naming the word and meaning columns
+// (e.g. "word,meaning" or "단어,뜻") is honoured; otherwise the first column is the
+// word and the second the meaning.
+func parseDelimited(content string, sep rune) ([]VocabPair, []VocabDiagnostic, error) {
+	r := csv.NewReader(strings.NewReader(content))
+	r.Comma = sep
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.Comment = '#'
+	records, lines, err := readRecords(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+
+	wordCol, meaningCol := 0, 1
+	if w, m := columnIndex(records[0], wordColumnNames), columnIndex(records[0], meaningColumnNames); w >= 0 && m >= 0 {
+		wordCol, meaningCol = w, m
+		records, lines = records[1:], lines[1:]
+	}
+	pairs, skipped := pairsFromRecords(records, lines, wordCol, meaningCol)
+	return pairs, skipped, nil
+}
+
+// readRecords reads every record with the line of the file it starts on.
+func readRecords(r *csv.Reader) (records [][]string, lines []int, err error) {
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, lines, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := r.FieldPos(0)
+		records = append(records, rec)
+		lines = append(lines, line)
+	}
+}
+
+// pairsFromRecords reads the word and meaning columns of every record, with an
+// error diagnostic at the record's line for each one that is skipped.
+func pairsFromRecords(records [][]string, lines []int, wordCol, meaningCol int) ([]VocabPair, []VocabDiagnostic) {
+	var pairs []VocabPair
+	var skipped []VocabDiagnostic
+	skip := func(i int, format string, args ...any) {
+		skipped = append(skipped, VocabDiagnostic{Line: lines[i], Severity: SeverityError, Reason: fmt.Sprintf(format, args...) + "; row skipped"})
+	}
+	for i, rec := range records {
+		if wordCol >= len(rec) || meaningCol >= len(rec) {
+			skip(i, "%d column(s), but the word and meaning are in columns %d and %d", len(rec), wordCol+1, meaningCol+1)
+			continue
+		}
+		word := strings.TrimSpace(rec[wordCol])
+		meanings := splitMeanings(rec[meaningCol])
+		switch {
+		case word == "":
+			skip(i, "empty word in column %d", wordCol+1)
+		case len(meanings) == 0:
+			skip(i, "no meaning in column %d", meaningCol+1)
+		default:
+			pairs = append(pairs, VocabPair{Word: word, Meanings: meanings})
+		}
+	}
+	return pairs, skipped
+}
+
+func sniffTSV(content string) bool {
+	lines := nonEmptyLines(content, 5)
+	if len(lines) == 0 {
+		return false
+	}
+	for _, l := range lines {
+		if !strings.Contains(l, "\t") {
+			return false
+		}
+	}
+	return true
+}
+
+// sniffCSV recognises comma-separated content without an extension, as read
+// from stdin: a header row naming the word and meaning columns, or rows with
+// the same number (at least two) of fields. Lines with '=' are the text format.
+func sniffCSV(content string) bool {
+	var lines []string
+	for _, l := range nonEmptyLines(content, 6) {
+		if strings.Contains(l, "=") {
+			return false
+		}
+		if !strings.HasPrefix(l, "#") {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) == 0 {
+		return false
+	}
+	r := csv.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil || len(records) == 0 {
+		return false
+	}
+	if columnIndex(records[0], wordColumnNames) >= 0 && columnIndex(records[0], meaningColumnNames) >= 0 {
+		return true
+	}
+	for _, rec := range records {
+		if len(rec) < 2 || len(rec) != len(records[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sniffJSON requires the whole content to be valid JSON, since a text list
+// may start with a "[Unit 1]" heading.
+func sniffJSON(content string) bool {
+	t := strings.TrimSpace(content)
+	return (strings.HasPrefix(t, "[") || strings.HasPrefix(t, "{")) && json.Valid([]byte(t))
+}
+
+// parseVocabJSON accepts an array of objects ({"word": ..., "meanings": [...]} or
+// "meaning": "a, b"), an array of [word, meaning] pairs, or an object mapping word to meaning(s).
+func parseVocabJSON(content string) ([]VocabPair, error) {
+	var raw any
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	var pairs []VocabPair
+	add := func(word string, meaning any) {
+		word = strings.TrimSpace(word)
+		meanings := jsonMeanings(meaning)
+		if word != "" && len(meanings) > 0 {
+			pairs = append(pairs, VocabPair{Word: word, Meanings: meanings})
+		}
+	}
+
+	switch v := raw.(type) {
+	case map[string]any:
+		for _, key := range sortedKeys(v) {
+			add(key, v[key])
+		}
+	case []any:
+		for i, entry := range v {
+			switch e := entry.(type) {
+			case map[string]any:
+				word, meaning := jsonField(e, wordColumnNames), jsonField(e, meaningColumnNames)
+				w, _ := word.(string)
+				add(w, meaning)
+			case []any:
+				if len(e) >= 2 {
+					w, _ := e[0].(string)
+					add(w, e[1])
+				}
+			default:
+				return nil, fmt.Errorf("%d번째 항목을 읽을 수 없습니다", i+1)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("JSON 최상위 값은 배열이나 객체여야 합니다")
+	}
+	return pairs, nil
+}
+
+func jsonField(obj map[string]any, names []string) any {
+	for k, v := range obj {
+		lk := strings.ToLower(k)
+		for _, n := range names {
+			if lk == n {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+func jsonMeanings(v any) []string {
+	switch m := v.(type) {
+	case string:
+		return splitMeanings(m)
+	case []any:
+		var out []string
+		for _, item := range m {
+			if s, ok := item.(string); ok {
+				out = append(out, splitMeanings(s)...)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	// JSON objects have no order of their own; keep the output stable.
+	sort.Strings(keys)
+	return keys
+}
+
+// sniffAnki recognises Anki's "Notes in Plain Text" export, which starts with
+// "#separator:" / "#html:" header lines.
+func sniffAnki(content string) bool {
+	for _, l := range nonEmptyLines(content, 6) {
+		if !strings.HasPrefix(l, "#") {
+			return false
+		}
+		if strings.HasPrefix(l, "#separator:") || strings.HasPrefix(l, "#html:") {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ankiBreakRe = regexp.MustCompile(`(?i)<br\s*/?>|</div>|</p>`)
+	ankiTagRe   = regexp.MustCompile(`<[^>]*>`)
+	ankiSoundRe = regexp.MustCompile(`\[sound:[^\]]*\]`)
+)
+
+var ankiSeparators = map[string]rune{
+	"tab": '\t', "comma": ',', "semicolon": ';', "pipe": '|', "space": ' ', "colon": ':',
+}
+
+// parseAnki reads an Anki plain-text export. The header lines choose the
+// separator and, with "#columns:", which columns hold the word and meaning;
+// otherwise the first two non-guid/notetype/deck columns are used.
+func parseAnki(content string) ([]VocabPair, []VocabDiagnostic, error) {
+	sep := '\t'
+	isHTML := false
+	var columns []string
+	skip := make(map[int]bool)
+
+	var body bytes.Buffer
+	var fileLines []int // line of the file of every line of body
+	for i, line := range strings.Split(content, "\n") {
+		t := strings.TrimRight(line, "\r")
+		if !strings.HasPrefix(t, "#") {
+			body.WriteString(t + "\n")
+			fileLines = append(fileLines, i+1)
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimPrefix(t, "#"), ":")
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "separator":
+			v := strings.ToLower(strings.TrimSpace(value))
+			if r, ok := ankiSeparators[v]; ok {
+				sep = r
+			} else if len(v) == 1 {
+				sep = rune(v[0])
+			}
+		case "html":
+			isHTML = strings.TrimSpace(value) == "true"
+		case "columns":
+			columns = strings.Split(value, string(sep))
+		case "guid column", "notetype column", "deck column", "tags column":
+			if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				skip[n-1] = true
+			}
+		}
+	}
+
+	r := csv.NewReader(&body)
+	r.Comma = sep
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, lines, err := readRecords(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, l := range lines {
+		lines[i] = fileLines[l-1]
+	}
+
+	wordCol, meaningCol := -1, -1
+	if columns != nil {
+		wordCol, meaningCol = columnIndex(columns, wordColumnNames), columnIndex(columns, meaningColumnNames)
+	}
+	if wordCol < 0 || meaningCol < 0 {
+		var free []int
+		for i := 0; len(free) < 2 && i < 16; i++ {
+			if !skip[i] {
+				free = append(free, i)
+			}
+		}
+		wordCol, meaningCol = free[0], free[1]
+	}
+
+	for _, rec := range records {
+		for i := range rec {
+			rec[i] = cleanAnkiField(rec[i], isHTML)
+		}
+	}
+	pairs, skipped := pairsFromRecords(records, lines, wordCol, meaningCol)
+	return pairs, skipped, nil
+}
+
+func cleanAnkiField(s string, isHTML bool) string {
+	s = ankiSoundRe.ReplaceAllString(s, "")
+	if isHTML || strings.Contains(s, "<") {
+		s = ankiBreakRe.ReplaceAllString(s, ", ")
+		s = ankiTagRe.ReplaceAllString(s, "")
+		s = html.UnescapeString(s)
+	}
+	return strings.TrimSpace(strings.ReplaceAll(s, "\u00a0", " "))
+}
+
+func nonEmptyLines(content string, limit int) []string {
+	var lines []string
+	for _, l := range strings.Split(content, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+			if len(lines) == limit {
+				break
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindImporter(t *testing.T) {
+	tests := []struct {
+		name, path, content string
+		want                string
+	}{
+		{"text", "list.txt", "bank = 은행\n", "Text"},
+		{"csv by extension", "list.csv", "bank,은행\n", "CSV"},
+		{"csv from stdin", "", "word,meaning\nbank,은행\n", "CSV"},
+		{"csv without header", "", "bank,\"은행, 둑\"\nrun,달리다\n", "CSV"},
+		{"text with commas", "", "bank = 은행, 둑\nrun = 달리다, 운영하다\n", "Text"},
+		{"ragged commas", "", "bank, 은행\nrun\n", "Text"},
+		{"tab-separated txt", "list.txt", "bank\t은행\nrun\t달리다\n", "TSV"},
+		{"json", "", `[{"word": "bank", "meaning": "은행"}]`, "JSON"},
+		{"text with a unit heading", "", "[Unit 1]\nconduct = 행동\n", "Text"},
+		{"anki", "deck.txt", "#separator:tab\n#html:false\nbank\t은행\n", "Anki"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findImporter(tt.path, tt.content).name; got != tt.want {
+				t.Errorf("findImporter(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDelimited(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		sep     rune
+		want    []VocabPair
+		skipped []VocabDiagnostic
+	}{
+		{
+			name:    "header",
+			content: "뜻,단어\n\"은행, 둑\",bank\n",
+			sep:     ',',
+			want:    []VocabPair{{Word: "bank", Meanings: []string{"은행", "둑"}}},
+		},
+		{
+			name:    "skipped rows",
+			content: "word,meaning\nbank,은행\nrun\n,달리다\n# comment\nfair,\n\"multi\nline\",공정한\n",
+			sep:     ',',
+			want: []VocabPair{
+				{Word: "bank", Meanings: []string{"은행"}},
+				{Word: "multi\nline", Meanings: []string{"공정한"}},
+			},
+			skipped: []VocabDiagnostic{
+				{Line: 3, Severity: SeverityError, Reason: "1 column(s), but the word and meaning are in columns 1 and 2; row skipped"},
+				{Line: 4, Severity: SeverityError, Reason: "empty word in column 1; row skipped"},
+				{Line: 6, Severity: SeverityError, Reason: "no meaning in column 2; row skipped"},
+			},
+		},
+		{
+			name:    "tsv",
+			content: "bank\t은행; 둑\n",
+			sep:     '\t',
+			want:    []VocabPair{{Word: "bank", Meanings: []string{"은행", "둑"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped, err := parseDelimited(tt.content, tt.sep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pairs = %#v, want %#v", got, tt.want)
+			}
+			if !reflect.DeepEqual(skipped, tt.skipped) {
+				t.Errorf("skipped = %v, want %v", skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestImportVocab(t *testing.T) {
+	pairs, _, err := importVocab("-", []byte("[Unit 1]\nconduct = 행동\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []VocabPair{{Word: "conduct", Meanings: []string{"행동"}, Unit: "Unit 1"}}; !reflect.DeepEqual(pairs, want) {
+		t.Errorf("pairs = %#v, want %#v", pairs, want)
+	}
+
+	pairs, diags, err := importVocab("list.csv", []byte("\ufeffword,meaning\nbank,은행\n,둑\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []VocabPair{{Word: "bank", Meanings: []string{"은행"}}}
+	if !reflect.DeepEqual(pairs, want) {
+		t.Errorf("pairs = %#v, want %#v", pairs, want)
+	}
+	if len(diags) != 1 || diags[0].Line != 3 {
+		t.Errorf("diagnostics = %v, want the row on line 3", diags)
+	}
+}
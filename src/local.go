@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// localBuilder makes questions for vocab straight from the list, without a
+// model, drawing distractors from pool. The correct choice may be put anywhere;
+// balanceAnswers places it afterwards.
+type localBuilder func(vocab, pool []VocabPair, rng *rand.Rand) ([]Question, error)
+
+// providerOffline is the pseudo provider under which the local question types
+// are offered. It needs no API key.
+const providerOffline = "offline"
+
+// matchingTableSize is the number of words per word–meaning matching table.
+const matchingTableSize = 10
+
+// localQuestionTypes are offered under the offline provider. Their content comes
+// entirely from the list, so they never call the API and the same seed always
+// gives the same paper.
+var localQuestionTypes = []QuestionType{
+	{ID: "영단어 → 우리말 뜻", Description: "영단어의 뜻 고르기", Local: buildWordToMeaning},
+	{ID: "우리말 뜻 → 영단어", Description: "우리말 뜻에 맞는 영단어 고르기", AnswerIsWord: true, Local: buildMeaningToWord},
+	{ID: "철자 배열", Description: "뒤섞인 철자를 바르게 배열해 쓰기", Local: buildSpellingScramble},
+	{ID: "첫 글자 힌트", Description: "첫 글자를 보고 단어 쓰기 (예문이 있으면 예문 속 빈칸)", Local: buildFirstLetterHint},
+	{ID: "단어-뜻 연결", Description: "단어와 뜻을 연결하는 표", Local: buildMatchingTable},
+}
+
+// runLocalGeneration builds the paper of a local question type. It goes through
+// the same balancing and validation as model output.
+func runLocalGeneration(job GenerationJob, qt QuestionType) (GenerationResult, error) {
+	if len(job.Vocab) == 0 {
+		return GenerationResult{}, fmt.Errorf("생성할 단어가 없습니다. 'word = meaning' 형식을 확인하세요")
+	}
+	seed := job.AnswerSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	pool := job.DistractorPool
+	if len(pool) == 0 {
+		pool = job.Vocab
+	}
+	questions, err := qt.Local(job.Vocab, pool, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return GenerationResult{}, err
+	}
+	renumberQuestions(questions)
+	setQuestionType(questions, qt.ID)
+	balanceAnswers(questions, job.AnswerWeights, seed)
+	return GenerationResult{
+		Text:      formatQuestions(questions),
+		Questions: questions,
+		Issues:    validateQuestions(questions, job.Vocab, job.QuestionType),
+		Seed:      seed,
+	}, nil
+}
+
+// buildWordToMeaning asks for the Korean meaning of each word. One of the word's
+// meanings is the answer; the distractors are meanings of other entries.
+func buildWordToMeaning(vocab, pool []VocabPair, rng *rand.Rand) ([]Question, error) {
+	var questions []Question
+	for _, v := range vocab {
+		sense := v.Meanings[rng.Intn(len(v.Meanings))]
+		used := make(map[string]bool)
+		for _, m := range v.Meanings {
+			used[m] = true
+		}
+
+		choices := []string{sense}
+		for _, d := range pickDistractors(pool, v, rng) {
+			// Use a meaning of the other entry that does not overlap with this word or earlier choices.
+			for _, k := range rng.Perm(len(d.Meanings)) {
+				if m := d.Meanings[k]; !used[m] {
+					used[m] = true
+					choices = append(choices, m)
+					break
+				}
+			}
+			if len(choices) == numChoices {
+				break
+			}
+		}
+		if len(choices) < numChoices {
+			return nil, errTooFewEntries(v.Word)
+		}
+
+		questions = append(questions, Question{
+			Prompt:  "다음 영단어의 뜻으로 가장 알맞은 것은?",
+			Context: []string{headword(v)},
+			Choices: choices,
+			Answer:  0,
+			Word:    v.Word,
+			POS:     v.POS,
+			Sense:   sense,
+		})
+	}
+	return questions, nil
+}
+
+// buildMeaningToWord shows the Korean meanings of each word and asks for the
+// word; the distractors are other words of the list.
+func buildMeaningToWord(vocab, pool []VocabPair, rng *rand.Rand) ([]Question, error) {
+	var questions []Question
+	for _, v := range vocab {
+		used := map[string]bool{strings.ToLower(v.Word): true}
+		choices := []string{v.Word}
+		for _, d := range pickDistractors(pool, v, rng) {
+			if key := strings.ToLower(d.Word); !used[key] {
+				used[key] = true
+				choices = append(choices, d.Word)
+			}
+			if len(choices) == numChoices {
+				break
+			}
+		}
+		if len(choices) < numChoices {
+			return nil, errTooFewEntries(v.Word)
+		}
+
+		prompt := "다음 뜻을 가진 영단어로 가장 알맞은 것은?"
+		if v.POS != "" {
+			prompt = fmt.Sprintf("다음 뜻을 가진 영단어(%s)로 가장 알맞은 것은?", v.POS)
+		}
+		questions = append(questions, Question{
+			Prompt:  prompt,
+			Context: []string{strings.Join(v.Meanings, ", ")},
+			Choices: choices,
+			Answer:  0,
+			Word:    v.Word,
+			POS:     v.POS,
+		})
+	}
+	return questions, nil
+}
+
+// buildSpellingScramble shows the meanings and the word's letters in random
+// order; the answer is written.
+func buildSpellingScramble(vocab, _ []VocabPair, rng *rand.Rand) ([]Question, error) {
+	var questions []Question
+	for _, v := range vocab {
+		var letters []rune
+		for _, r := range strings.ToLower(v.Word) {
+			if r != ' ' && r != '-' {
+				letters = append(letters, r)
+			}
+		}
+		original := string(letters)
+		// Reshuffle a few times so the letters are not shown in the right order.
+		for try := 0; try < 10; try++ {
+			rng.Shuffle(len(letters), func(i, j int) { letters[i], letters[j] = letters[j], letters[i] })
+			if string(letters) != original {
+				break
+			}
+		}
+		parts := make([]string, len(letters))
+		for i, r := range letters {
+			parts[i] = string(r)
+		}
+		scrambled := strings.Join(parts, " / ")
+		if n := len(strings.Fields(v.Word)); n > 1 {
+			scrambled += fmt.Sprintf(" (%d단어)", n)
+		}
+
+		questions = append(questions, Question{
+			Prompt:     "다음 뜻을 가진 단어가 되도록 철자를 바르게 배열하여 쓰시오.",
+			Context:    []string{strings.Join(v.Meanings, ", "), scrambled},
+			Answer:     -1,
+			AnswerText: v.Word,
+			Word:       v.Word,
+			POS:        v.POS,
+		})
+	}
+	return questions, nil
+}
+
+// buildFirstLetterHint gives the meaning and the first letter of the word. When
+// the entry has an example sentence containing the word, the hint is shown in
+// that sentence instead.
+func buildFirstLetterHint(vocab, _ []VocabPair, rng *rand.Rand) ([]Question, error) {
+	var questions []Question
+	for _, v := range vocab {
+		hint := letterHint(v.Word)
+		context := []string{fmt.Sprintf("%s: %s", strings.Join(v.Meanings, ", "), hint)}
+		sense := ""
+		for i, ex := range v.Examples {
+			if ex == "" {
+				continue
+			}
+			if start, end := indexWord(ex, v.Word); start >= 0 {
+				sense = v.Meanings[i]
+				context = []string{sense, ex[:start] + hint + ex[end:]}
+				break
+			}
+		}
+
+		questions = append(questions, Question{
+			Prompt:     "주어진 첫 글자로 시작하는, 뜻에 맞는 단어를 쓰시오.",
+			Context:    context,
+			Answer:     -1,
+			AnswerText: v.Word,
+			Word:       v.Word,
+			POS:        v.POS,
+			Sense:      sense,
+		})
+	}
+	return questions, nil
+}
+
+// letterHint keeps the first letter of every word of a phrase and blanks the rest,
+// e.g. "take off" -> "t___ o__".
+func letterHint(word string) string {
+	var parts []string
+	for _, w := range strings.Fields(word) {
+		r := []rune(w)
+		parts = append(parts, string(r[0])+strings.Repeat("_", len(r)-1))
+	}
+	return strings.Join(parts, " ")
+}
+
+// indexWord finds word in sentence, ignoring case, on letter boundaries. It
+// returns where the match starts and ends in sentence, or -1, -1. The search
+// runs on sentence itself, since lowercasing may change the length of a string.
+func indexWord(sentence, word string) (start, end int) {
+	for i := range sentence {
+		n := prefixFold(sentence[i:], word)
+		if n < 0 {
+			continue
+		}
+		if (i == 0 || !isASCIILetter(sentence[i-1])) && (i+n == len(sentence) || !isASCIILetter(sentence[i+n])) {
+			return i, i + n
+		}
+	}
+	return -1, -1
+}
+
+// prefixFold returns the length of the prefix of s that equals prefix under
+// Unicode case folding, or -1.
+func prefixFold(s, prefix string) int {
+	n := 0
+	for _, r := range prefix {
+		c, size := utf8.DecodeRuneInString(s[n:])
+		if size == 0 || (c != r && !strings.EqualFold(string(c), string(r))) {
+			return -1
+		}
+		n += size
+	}
+	return n
+}
+
+// buildMatchingTable puts the words in tables of matchingTableSize rows, with
+// the meanings of the same words lettered in random order on the right.
+func buildMatchingTable(vocab, _ []VocabPair, rng *rand.Rand) ([]Question, error) {
+	marks := []string{"ⓐ", "ⓑ", "ⓒ", "ⓓ", "ⓔ", "ⓕ", "ⓖ", "ⓗ", "ⓘ", "ⓙ"}
+	var questions []Question
+	for _, chunk := range chunkVocab(vocab, matchingTableSize) {
+		width := 0
+		for _, v := range chunk {
+			width = max(width, len(headword(v)))
+		}
+		order := rng.Perm(len(chunk)) // order[row] is the entry whose meaning is on that row
+		position := make([]int, len(chunk))
+		for row, k := range order {
+			position[k] = row
+		}
+
+		var rows, key, words []string
+		for i, v := range chunk {
+			rows = append(rows, fmt.Sprintf("(%d) %-*s   %s %s", i+1, width, headword(v), marks[i], strings.Join(chunk[order[i]].Meanings, ", ")))
+			key = append(key, fmt.Sprintf("(%d)-%s", i+1, marks[position[i]]))
+			words = append(words, headword(v))
+		}
+		questions = append(questions, Question{
+			Prompt:     "다음 단어와 뜻을 알맞게 연결하시오.",
+			Context:    rows,
+			Answer:     -1,
+			AnswerText: strings.Join(key, " "),
+			Words:      words,
+		})
+	}
+	return questions, nil
+}
+
+// pickDistractors returns the entries of pool for other words in random order,
+// those with the same part of speech as self first. Entries of the same word
+// with another part of speech are left out, since their meanings are also correct.
+func pickDistractors(pool []VocabPair, self VocabPair, rng *rand.Rand) []VocabPair {
+	var same, other []VocabPair
+	for _, k := range rng.Perm(len(pool)) {
+		if strings.EqualFold(pool[k].Word, self.Word) {
+			continue
+		}
+		if pool[k].POS != "" && pool[k].POS == self.POS {
+			same = append(same, pool[k])
+		} else {
+			other = append(other, pool[k])
+		}
+	}
+	return append(same, other...)
+}
+
+func errTooFewEntries(word string) error {
+	return fmt.Errorf("'%s'의 보기를 만들 수 없습니다. 서로 다른 단어가 %d개 이상 필요합니다", word, numChoices)
+}
+
+// headword is the word as shown in a question, with its part of speech.
+func headword(v VocabPair) string {
+	if v.POS == "" {
+		return v.Word
+	}
+	return fmt.Sprintf("%s (%s)", v.Word, v.POS)
+}
+
+// splitHeadword reads a headword back into the word and its part of speech.
+func splitHeadword(s string) (word, pos string) {
+	if m := posSuffixRe.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+		return m[1], m[2]
+	}
+	return strings.TrimSpace(s), ""
+}
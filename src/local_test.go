@@ -0,0 +1,205 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testVocab is a list with enough distinct words for the multiple-choice types.
+func testVocab() []VocabPair {
+	parsed, _ := parseVocabLines(`bank (n.) = 은행, 둑 "He sat on the bank of the river."
+conduct (n.) = 행동
+conduct (v.) = 수행하다
+fair = 공정한
+bear = 참다, 곰
+light = 가벼운, 빛
+run = 달리다
+take off = 이륙하다`)
+	return parsed
+}
+
+func TestIndexWord(t *testing.T) {
+	tests := []struct {
+		sentence, word string
+		match          string // the matched text, "" for none
+	}{
+		{"The BANK was closed.", "bank", "BANK"},
+		{"bank", "Bank", "bank"},
+		{"An embankment, then a bank.", "bank", "bank"},
+		{"Banks open late.", "bank", ""},
+		{"İstanbul's Bank closed.", "bank", "Bank"},
+		{"The Kelvin scale.", "kelvin", "Kelvin"}, // the Kelvin sign is longer than 'K'
+		{"Planes Take Off at noon.", "take off", "Take Off"},
+		{"은행(bank)에 갔다.", "bank", "bank"},
+		{"", "bank", ""},
+	}
+	for _, tt := range tests {
+		start, end := indexWord(tt.sentence, tt.word)
+		if tt.match == "" {
+			if start != -1 || end != -1 {
+				t.Errorf("indexWord(%q, %q) = %d, %d, want no match", tt.sentence, tt.word, start, end)
+			}
+			continue
+		}
+		if start < 0 || tt.sentence[start:end] != tt.match {
+			t.Errorf("indexWord(%q, %q) = %d, %d, want %q", tt.sentence, tt.word, start, end, tt.match)
+		}
+	}
+}
+
+func TestBuildFirstLetterHint(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		context []string
+		sense   string
+	}{
+		{"no example", "bank = 은행, 둑", []string{"은행, 둑: b___"}, ""},
+		{"example", `bank = 은행, 둑 "He sat on the BANK of the river."`, []string{"둑", "He sat on the b___ of the river."}, "둑"},
+		{"example of the second sense", `bank = 은행, 둑 "Banks open late.", 둑 "İstanbul's Bank flooded."`, []string{"둑", "İstanbul's b___ flooded."}, "둑"},
+		{"phrase", `take off = 이륙하다 "Planes Take Off at noon."`, []string{"이륙하다", "Planes t___ o__ at noon."}, "이륙하다"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vocab, _ := parseVocabLines(tt.entry)
+			questions, err := buildFirstLetterHint(vocab, nil, rand.New(rand.NewSource(1)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			q := questions[0]
+			if !reflect.DeepEqual(q.Context, tt.context) || q.Sense != tt.sense || q.AnswerText != vocab[0].Word {
+				t.Errorf("context %q, sense %q, answer %q; want %q, %q, %q", q.Context, q.Sense, q.AnswerText, tt.context, tt.sense, vocab[0].Word)
+			}
+		})
+	}
+}
+
+func TestRunLocalGenerationIsDeterministic(t *testing.T) {
+	for _, qt := range localQuestionTypes {
+		t.Run(qt.ID, func(t *testing.T) {
+			job := GenerationJob{QuestionType: qt.ID, Vocab: testVocab(), AnswerSeed: 42}
+			first, err := runLocalGeneration(job, qt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := runLocalGeneration(job, qt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first.Text != again.Text || first.Seed != 42 {
+				t.Errorf("seed %d gave two papers:\n%s\n---\n%s", first.Seed, first.Text, again.Text)
+			}
+			if len(first.Issues) > 0 {
+				t.Errorf("validation issues: %v", first.Issues)
+			}
+		})
+	}
+}
+
+func TestLocalDistractorsComeFromThePool(t *testing.T) {
+	pool := testVocab()
+	vocab := pool[1:3] // conduct (n.) and conduct (v.)
+	for _, id := range []string{"영단어 → 우리말 뜻", "우리말 뜻 → 영단어"} {
+		t.Run(id, func(t *testing.T) {
+			qt, _ := findQuestionType(id)
+			if _, err := runLocalGeneration(GenerationJob{QuestionType: id, Vocab: vocab, AnswerSeed: 1}, qt); err == nil {
+				t.Fatal("two entries of one word made five choices without a pool")
+			}
+			result, err := runLocalGeneration(GenerationJob{QuestionType: id, Vocab: vocab, DistractorPool: pool, AnswerSeed: 1}, qt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Questions) != 2 {
+				t.Fatalf("%d questions", len(result.Questions))
+			}
+			for _, q := range result.Questions {
+				for i, c := range q.Choices {
+					if i == q.Answer {
+						continue
+					}
+					// Entries of the same word are never distractors: their meanings are also right.
+					from := ""
+					for _, v := range pool {
+						if strings.EqualFold(v.Word, c) || indexOfChoice(v.Meanings, c) >= 0 {
+							from = v.Word
+						}
+					}
+					if from == "" || from == "conduct" {
+						t.Errorf("question %d: distractor %q is from %q", q.Number, c, from)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLocalBuildersNeedFiveWords(t *testing.T) {
+	vocab := testVocab()[:4] // conduct is listed twice, so only three words
+	for _, build := range []localBuilder{buildWordToMeaning, buildMeaningToWord} {
+		_, err := build(vocab, vocab, rand.New(rand.NewSource(1)))
+		if err == nil || !strings.Contains(err.Error(), "서로 다른 단어가 5개 이상 필요합니다") {
+			t.Errorf("error = %v", err)
+		}
+	}
+}
+
+func TestBuildSpellingScramble(t *testing.T) {
+	vocab := testVocab()
+	questions, err := buildSpellingScramble(vocab, nil, rand.New(rand.NewSource(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted := func(s string) string {
+		r := []rune(s)
+		sort.Slice(r, func(i, j int) bool { return r[i] < r[j] })
+		return string(r)
+	}
+	for i, q := range questions {
+		word := vocab[i].Word
+		scrambled := q.Context[1]
+		if strings.Contains(word, " ") {
+			if !strings.HasSuffix(scrambled, " (2단어)") {
+				t.Errorf("%q: %q does not give the number of words", word, scrambled)
+			}
+			scrambled = strings.TrimSuffix(scrambled, " (2단어)")
+		}
+		letters := strings.ReplaceAll(scrambled, " / ", "")
+		if sorted(letters) != sorted(strings.ReplaceAll(word, " ", "")) || letters == strings.ReplaceAll(word, " ", "") {
+			t.Errorf("%q scrambled as %q", word, q.Context[1])
+		}
+		if q.AnswerText != word || q.Answer != -1 {
+			t.Errorf("%q: answer %q", word, q.AnswerText)
+		}
+	}
+}
+
+func TestBuildMatchingTable(t *testing.T) {
+	var vocab []VocabPair
+	for _, w := range strings.Fields("a b c d e f g h i j k l") {
+		vocab = append(vocab, VocabPair{Word: w, Meanings: []string{"뜻" + w}})
+	}
+	questions, err := buildMatchingTable(vocab, nil, rand.New(rand.NewSource(9)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(questions) != 2 || len(questions[0].Context) != matchingTableSize || len(questions[1].Context) != 2 {
+		t.Fatalf("tables of %d questions", len(questions))
+	}
+	for _, q := range questions {
+		meanings := make(map[string]string) // mark -> meaning on its row
+		for _, row := range q.Context {
+			f := strings.Fields(row)
+			meanings[f[2]] = f[3]
+		}
+		for i, pair := range strings.Fields(q.AnswerText) {
+			word := q.Words[i]
+			mark := pair[strings.Index(pair, "-")+1:]
+			if meanings[mark] != "뜻"+word {
+				t.Errorf("%s is matched with %s %q", word, mark, meanings[mark])
+			}
+		}
+	}
+}
@@ -8,6 +8,10 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	m := initialModel()
 	p := tea.NewProgram(&m)
 
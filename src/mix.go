@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mixedQuestionType is the menu entry and job QuestionType of a mixed test.
+const mixedQuestionType = "혼합 (여러 유형)"
+
+// defaultMixes are offered when no mix was entered before.
+var defaultMixes = map[bool]string{
+	false: "빈칸 추론 40, 영영풀이 30, 뜻풀이 판단 30",
+	true:  "영단어 → 우리말 뜻 40, 우리말 뜻 → 영단어 30, 철자 배열 30",
+}
+
+// MixPart is one question type of a mixed test and its share of the words.
+type MixPart struct {
+	QuestionType string
+	Percent      int
+}
+
+// mixEntryRe reads one "type percent" entry of a mix, e.g. "빈칸 추론 40%" or "영영풀이:30".
+var mixEntryRe = regexp.MustCompile(`^(.*?)\s*[:=]?\s*(\d+)\s*%?$`)
+
+// parseMix reads a mix written as "빈칸 추론 40, 영영풀이 30, 뜻풀이 판단 30".
+// Question type names may omit spaces. The shares are relative and need not
+// add up to 100.
+func parseMix(spec string) ([]MixPart, error) {
+	var parts []MixPart
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		m := mixEntryRe.FindStringSubmatch(entry)
+		if m == nil {
+			return nil, fmt.Errorf("%q: '유형 비율' 형식이어야 합니다 (예: 빈칸 추론 40)", entry)
+		}
+		id, ok := resolveQuestionType(m[1])
+		if !ok {
+			return nil, fmt.Errorf("%q: 알 수 없는 문제 유형입니다", m[1])
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%q 유형이 두 번 있습니다", id)
+		}
+		seen[id] = true
+		percent, _ := strconv.Atoi(m[2])
+		parts = append(parts, MixPart{QuestionType: id, Percent: percent})
+	}
+	total := 0
+	for _, p := range parts {
+		total += p.Percent
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("문제 유형과 비율을 하나 이상 적어야 합니다")
+	}
+	return parts, nil
+}
+
+// formatMix writes a mix back in the syntax parseMix reads.
+func formatMix(parts []MixPart) string {
+	var entries []string
+	for _, p := range parts {
+		entries = append(entries, fmt.Sprintf("%s %d", p.QuestionType, p.Percent))
+	}
+	return strings.Join(entries, ", ")
+}
+
+// mixSentences reports whether any type of the mix asks for a sentence count.
+func mixSentences(parts []MixPart) bool {
+	for _, p := range parts {
+		if qt, _ := findQuestionType(p.QuestionType); qt.Sentences > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// assignMixWords splits vocab, in order, into one group per part sized by its
+// share. The caller shuffles the list first, so words land in random types.
+func assignMixWords(vocab []VocabPair, parts []MixPart) [][]VocabPair {
+	weights := make([]int, len(parts))
+	for i, p := range parts {
+		weights[i] = p.Percent
+	}
+	groups := make([][]VocabPair, len(parts))
+	start := 0
+	for i, n := range apportion(len(vocab), weights) {
+		groups[i] = vocab[start : start+n]
+		start += n
+	}
+	return groups
+}
+
+// runMixedGeneration generates every part of job.Mix as its own job, one after
+// the other, and merges them into one paper in the order of the mix. Each part
+// is validated and regenerated with its own type; the answers are balanced
+// again over the whole paper. Malformed blocks of a part are reported in
+// ParseErr but left out of the merged text.
+func runMixedGeneration(ctx context.Context, gen Generator, job GenerationJob) (GenerationResult, error) {
+	seed := job.AnswerSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	groups := assignMixWords(job.Vocab, job.Mix)
+
+	var merged GenerationResult
+	var parseErrs []error
+	for i, part := range job.Mix {
+		if len(groups[i]) == 0 {
+			continue
+		}
+		if qt, _ := findQuestionType(part.QuestionType); qt.Local == nil && gen == nil {
+			return merged, fmt.Errorf("'%s' 유형은 모델이 필요합니다. 오프라인에서는 오프라인 유형만 섞을 수 있습니다", part.QuestionType)
+		}
+		partJob := job
+		partJob.Mix = nil
+		partJob.QuestionType = part.QuestionType
+		partJob.Vocab = groups[i]
+		partJob.DistractorPool = job.Vocab
+		partJob.AnswerSeed = seed + int64(i)
+		// Parts run one after another; streaming them would mix their numbering.
+		partJob.OnDelta = nil
+		partJob.OnProgress = nil
+
+		result, err := runGeneration(ctx, gen, partJob)
+		if err != nil {
+			return merged, fmt.Errorf("%s: %w", part.QuestionType, err)
+		}
+		numbers := make(map[int]int) // number in the part -> number in the paper
+		for _, q := range result.Questions {
+			numbers[q.Number] = len(merged.Questions) + 1
+			q.Number = numbers[q.Number]
+			merged.Questions = append(merged.Questions, q)
+		}
+		for _, is := range result.Issues {
+			is.Number = numbers[is.Number]
+			is.Reason = fmt.Sprintf("[%s] %s", part.QuestionType, is.Reason)
+			merged.Issues = append(merged.Issues, is)
+		}
+		if result.ParseErr != nil {
+			parseErrs = append(parseErrs, fmt.Errorf("%s: %w", part.QuestionType, result.ParseErr))
+		}
+		if job.OnProgress != nil {
+			job.OnProgress(i+1, len(job.Mix))
+		}
+	}
+
+	merged.ParseErr = errors.Join(parseErrs...)
+	if len(merged.Questions) > 0 {
+		// Balance over the whole paper so the mix as a whole gets the target distribution.
+		balanceAnswers(merged.Questions, job.AnswerWeights, seed)
+		merged.Seed = seed
+	}
+	merged.Text = formatQuestions(merged.Questions)
+	return merged, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMix(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []MixPart
+		wantErr string
+	}{
+		{
+			spec: "빈칸 추론 40, 영영풀이 30, 뜻풀이 판단 30",
+			want: []MixPart{{"빈칸 추론", 40}, {"영영풀이", 30}, {"뜻풀이 판단", 30}},
+		},
+		{
+			spec: "빈칸추론 40%, 영영풀이:30,,",
+			want: []MixPart{{"빈칸 추론", 40}, {"영영풀이", 30}},
+		},
+		{
+			spec: "지문 빈칸 = 1, 동의어 고르기 0",
+			want: []MixPart{{"지문 빈칸", 1}, {"동의어 고르기", 0}},
+		},
+		{spec: "빈칸 추론", wantErr: "'유형 비율' 형식이어야 합니다"},
+		{spec: "받아쓰기 50", wantErr: "알 수 없는 문제 유형입니다"},
+		{spec: "빈칸 추론 50, 빈칸추론 50", wantErr: "두 번 있습니다"},
+		{spec: "빈칸 추론 0", wantErr: "하나 이상 적어야 합니다"},
+		{spec: "", wantErr: "하나 이상 적어야 합니다"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseMix(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMix = %v, want %v", got, tt.want)
+			}
+			again, err := parseMix(formatMix(got))
+			if err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("formatMix %q reads back as %v, %v", formatMix(got), again, err)
+			}
+		})
+	}
+}
+
+func TestAssignMixWords(t *testing.T) {
+	var vocab []VocabPair
+	for _, w := range strings.Fields("a b c d e f g h i j") {
+		vocab = append(vocab, VocabPair{Word: w})
+	}
+	groups := assignMixWords(vocab, []MixPart{{"빈칸 추론", 50}, {"영영풀이", 30}, {"뜻풀이 판단", 20}})
+	var sizes []int
+	var words []VocabPair
+	for _, g := range groups {
+		sizes = append(sizes, len(g))
+		words = append(words, g...)
+	}
+	if !reflect.DeepEqual(sizes, []int{5, 3, 2}) {
+		t.Errorf("group sizes = %v, want [5 3 2]", sizes)
+	}
+	if !reflect.DeepEqual(words, vocab) {
+		t.Errorf("groups do not cover the list in order: %v", words)
+	}
+}
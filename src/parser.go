@@ -1,46 +1,277 @@
 package main
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
+// VocabPair is one entry of the vocabulary list. Everything except Word and
+// Meanings is optional.
 type VocabPair struct {
 	Word     string
+	POS      string // part of speech as written, e.g. "n." or "v."
 	Meanings []string
+	Examples []string // Examples[i] illustrates Meanings[i]; nil when no sense has one
+	Unit     string   // set by a "[Unit 3]" heading line
+	Tags     []string // "#tag" words after the meanings
+	Note     string   // teacher's note after "//"
 }
 
+// Severity of a VocabDiagnostic. Errors mean the line was dropped; warnings mean
+// it was used but probably not as intended.
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// VocabDiagnostic reports a problem with one line of the vocabulary input.
+type VocabDiagnostic struct {
+	Line     int // 1-based
+	Severity Severity
+	Reason   string
+}
+
+func (d VocabDiagnostic) String() string {
+	return fmt.Sprintf("line %d: %s: %s", d.Line, d.Severity, d.Reason)
+}
+
+// fullWidthReplacer maps the full-width punctuation Korean IMEs produce to the
+// ASCII separators the format expects.
+var fullWidthReplacer = strings.NewReplacer("＝", "=", "，", ",", "；", ";", "、", ",")
+
+var (
+	senseSplitRe = regexp.MustCompile(`[;,]`)
+	posSuffixRe  = regexp.MustCompile(`^(.*?)\s*\(([^()]{1,8})\)$`)
+	unitLineRe   = regexp.MustCompile(`^\[([^\]=]+)\]$`)
+	tagRe        = regexp.MustCompile(`(^|\s)#(\S+)`)
+	exampleRe    = regexp.MustCompile(`^(.*?)\s*["“](.+)["”]$`)
+)
+
 // parseVocabBlock parses a block of text in "word = meaning1, meaning2; meaning3" format.
+// The full line syntax is
+//
+//	[Unit 3]
+//	conduct (n.) = 행동 "Her conduct was admirable.", 처신 #formal // 수능 빈출
+//
+// where the heading sets Unit for the lines below it, "(n.)" is the part of
+// speech, a quoted sentence after a meaning is an example of that sense, "#..."
+// words are tags and "//" starts a note.
 func parseVocabBlock(vocabBlock string) []VocabPair {
+	pairs, _ := parseVocabLines(vocabBlock)
+	return pairs
+}
+
+// parseVocabLines is parseVocabBlock with a diagnostic for every line that was
+// skipped or looks suspicious. Lines starting with '#' are comments.
+func parseVocabLines(vocabBlock string) ([]VocabPair, []VocabDiagnostic) {
 	var pairs []VocabPair
-	re := regexp.MustCompile(`[;,]`)
+	var diags []VocabDiagnostic
+	seen := make(map[string]int) // entry key -> line
+	unit := ""
+	report := func(line int, sev Severity, format string, args ...any) {
+		diags = append(diags, VocabDiagnostic{Line: line, Severity: sev, Reason: fmt.Sprintf(format, args...)})
+	}
 
-	for _, raw := range strings.Split(vocabBlock, "\n") {
+	for i, raw := range strings.Split(vocabBlock, "\n") {
+		lineNo := i + 1
 		line := strings.TrimSpace(raw)
-		if line == "" {
+		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 
+		if normalized := fullWidthReplacer.Replace(line); normalized != line {
+			report(lineNo, SeverityWarning, "full-width punctuation (＝，；) read as ASCII")
+			line = normalized
+		}
+		if m := unitLineRe.FindStringSubmatch(line); m != nil {
+			unit = strings.TrimSpace(m[1])
+			continue
+		}
+
+		note := ""
+		if i := indexOutsideQuotes(line, "//"); i >= 0 {
+			note = strings.TrimSpace(line[i+2:])
+			line = strings.TrimSpace(line[:i])
+		}
+
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) < 2 {
+			report(lineNo, SeverityError, "no '=' between word and meaning; line skipped")
 			continue
 		}
 
 		word := strings.TrimSpace(parts[0])
+		pos := ""
+		if m := posSuffixRe.FindStringSubmatch(word); m != nil {
+			word, pos = m[1], strings.TrimSpace(m[2])
+		}
 		meaningsRaw := strings.TrimSpace(parts[1])
+		var tags []string
+		for _, m := range tagRe.FindAllStringSubmatch(meaningsRaw, -1) {
+			tags = append(tags, m[2])
+		}
+		meaningsRaw = strings.TrimSpace(tagRe.ReplaceAllString(meaningsRaw, ""))
+		if word == "" {
+			report(lineNo, SeverityError, "missing word before '='; line skipped")
+			continue
+		}
 
-		senses := re.Split(meaningsRaw, -1)
-		var cleanSenses []string
-		for _, s := range senses {
+		var cleanSenses, examples []string
+		hasExample := false
+		for _, s := range splitSenses(meaningsRaw) {
 			s = strings.TrimSpace(s)
+			example := ""
+			if m := exampleRe.FindStringSubmatch(s); m != nil {
+				s, example = strings.TrimSpace(m[1]), strings.TrimSpace(m[2])
+				hasExample = true
+			}
 			if s != "" {
 				cleanSenses = append(cleanSenses, s)
+				examples = append(examples, example)
+			} else if example != "" {
+				report(lineNo, SeverityWarning, "example %q has no meaning before it; ignored", example)
+			}
+		}
+		if !hasExample {
+			examples = nil
+		}
+		if len(cleanSenses) == 0 {
+			report(lineNo, SeverityError, "no meaning after '='; line skipped")
+			continue
+		}
+
+		if r, ok := strayRune(word); ok {
+			report(lineNo, SeverityWarning, "unexpected character %q in word %q", r, word)
+		}
+		if strings.Contains(meaningsRaw, "=") {
+			report(lineNo, SeverityWarning, "more than one '='; everything after the first is meaning")
+		}
+		for _, s := range cleanSenses {
+			if looksEnglish(s) {
+				report(lineNo, SeverityWarning, "meaning %q looks English, not Korean", s)
+				break
+			}
+		}
+		key := entryKey(word, pos)
+		if first, dup := seen[key]; dup {
+			report(lineNo, SeverityWarning, "duplicate of %q on line %d", word, first)
+		} else {
+			seen[key] = lineNo
+		}
+
+		pairs = append(pairs, VocabPair{Word: word, POS: pos, Meanings: cleanSenses, Examples: examples, Unit: unit, Tags: tags, Note: note})
+	}
+	return pairs, diags
+}
+
+// key identifies the entry. The same word with another part of speech is a
+// separate entry.
+func (p VocabPair) key() string {
+	return entryKey(p.Word, p.POS)
+}
+
+// entryKey is the key of the entry of word with the given part of speech.
+func entryKey(word, pos string) string {
+	return strings.ToLower(strings.TrimSpace(word)) + "|" + normalizePOS(pos)
+}
+
+// normalizePOS makes "n.", "N" and " n. " the same part of speech.
+func normalizePOS(pos string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(pos)), ".")
+}
+
+// splitSenses splits the meaning part on ',' and ';' that are not inside a
+// quoted example sentence.
+func splitSenses(s string) []string {
+	var out []string
+	start, quoted := 0, false
+	for i, r := range s {
+		switch r {
+		case '"', '“', '”':
+			quoted = !quoted
+		case ',', ';':
+			if !quoted {
+				out = append(out, s[start:i])
+				start = i + 1
 			}
 		}
+	}
+	return append(out, s[start:])
+}
 
-		if word != "" && len(cleanSenses) > 0 {
-			pairs = append(pairs, VocabPair{Word: word, Meanings: cleanSenses})
+func indexOutsideQuotes(s, sub string) int {
+	quoted := false
+	for i, r := range s {
+		if r == '"' || r == '“' || r == '”' {
+			quoted = !quoted
+		} else if !quoted && strings.HasPrefix(s[i:], sub) {
+			return i
 		}
 	}
-	return pairs
+	return -1
+}
+
+// formatVocabLine renders p in the input syntax parseVocabLines reads, without
+// the unit heading.
+func formatVocabLine(p VocabPair) string {
+	var b strings.Builder
+	b.WriteString(p.Word)
+	if p.POS != "" {
+		fmt.Fprintf(&b, " (%s)", p.POS)
+	}
+	b.WriteString(" = ")
+	for i, m := range p.Meanings {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(m)
+		if i < len(p.Examples) && p.Examples[i] != "" {
+			b.WriteString(` "` + p.Examples[i] + `"`)
+		}
+	}
+	for _, t := range p.Tags {
+		b.WriteString(" #" + t)
+	}
+	if p.Note != "" {
+		b.WriteString(" // " + p.Note)
+	}
+	return b.String()
+}
+
+// strayRune returns the first rune of word that does not belong in an English
+// headword, such as list numbering, bullets or quotes.
+func strayRune(word string) (rune, bool) {
+	for _, r := range word {
+		if r < 0x80 && isASCIILetter(byte(r)) || r == ' ' || r == '-' || r == '\'' || r == '.' || r == '(' || r == ')' || r == '/' {
+			continue
+		}
+		return r, true
+	}
+	return 0, false
+}
+
+// looksEnglish reports a meaning written in Latin letters without any Hangul,
+// which usually means the columns were swapped or the line was left untranslated.
+func looksEnglish(s string) bool {
+	letters := 0
+	for _, r := range s {
+		if unicode.Is(unicode.Hangul, r) {
+			return false
+		}
+		if r < 0x80 && isASCIILetter(byte(r)) {
+			letters++
+		}
+	}
+	return letters >= 2
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVocabLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []VocabPair
+		diags []VocabDiagnostic
+	}{
+		{
+			name:  "plain",
+			input: "bank = 은행, 둑; 제방\nconduct = 행동",
+			want: []VocabPair{
+				{Word: "bank", Meanings: []string{"은행", "둑", "제방"}},
+				{Word: "conduct", Meanings: []string{"행동"}},
+			},
+		},
+		{
+			name:  "full syntax",
+			input: "[Unit 3]\nconduct (n.) = 행동 \"Her conduct was admirable.\", 처신 #formal // 수능 빈출",
+			want: []VocabPair{{
+				Word: "conduct", POS: "n.", Meanings: []string{"행동", "처신"},
+				Examples: []string{"Her conduct was admirable.", ""},
+				Unit:     "Unit 3", Tags: []string{"formal"}, Note: "수능 빈출",
+			}},
+		},
+		{
+			name:  "comments and blank lines",
+			input: "# Unit 1\n\nbank = 은행\n",
+			want:  []VocabPair{{Word: "bank", Meanings: []string{"은행"}}},
+		},
+		{
+			name:  "skipped lines",
+			input: "bank 은행\n= 은행\nbank =\nrun = 달리다",
+			want:  []VocabPair{{Word: "run", Meanings: []string{"달리다"}}},
+			diags: []VocabDiagnostic{
+				{Line: 1, Severity: SeverityError, Reason: "no '=' between word and meaning; line skipped"},
+				{Line: 2, Severity: SeverityError, Reason: "missing word before '='; line skipped"},
+				{Line: 3, Severity: SeverityError, Reason: "no meaning after '='; line skipped"},
+			},
+		},
+		{
+			name:  "full-width punctuation",
+			input: "bank ＝ 은행，둑",
+			want:  []VocabPair{{Word: "bank", Meanings: []string{"은행", "둑"}}},
+			diags: []VocabDiagnostic{{Line: 1, Severity: SeverityWarning, Reason: "full-width punctuation (＝，；) read as ASCII"}},
+		},
+		{
+			name:  "english meaning",
+			input: "bank = river side",
+			want:  []VocabPair{{Word: "bank", Meanings: []string{"river side"}}},
+			diags: []VocabDiagnostic{{Line: 1, Severity: SeverityWarning, Reason: `meaning "river side" looks English, not Korean`}},
+		},
+		{
+			name:  "same word with another part of speech",
+			input: "conduct (n.) = 행동\nconduct (v.) = 수행하다",
+			want: []VocabPair{
+				{Word: "conduct", POS: "n.", Meanings: []string{"행동"}},
+				{Word: "conduct", POS: "v.", Meanings: []string{"수행하다"}},
+			},
+		},
+		{
+			name:  "duplicate entry",
+			input: "conduct (n.) = 행동\nConduct (N) = 처신",
+			want: []VocabPair{
+				{Word: "conduct", POS: "n.", Meanings: []string{"행동"}},
+				{Word: "Conduct", POS: "N", Meanings: []string{"처신"}},
+			},
+			diags: []VocabDiagnostic{{Line: 2, Severity: SeverityWarning, Reason: `duplicate of "Conduct" on line 1`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diags := parseVocabLines(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pairs = %#v, want %#v", got, tt.want)
+			}
+			if !reflect.DeepEqual(diags, tt.diags) {
+				t.Errorf("diagnostics = %v, want %v", diags, tt.diags)
+			}
+		})
+	}
+}
+
+func TestEntryKey(t *testing.T) {
+	tests := []struct {
+		word, pos string
+		want      string
+	}{
+		{"conduct", "n.", "conduct|n"},
+		{"Conduct", " N ", "conduct|n"},
+		{"conduct", "", "conduct|"},
+		{"run", "V.", "run|v"},
+	}
+	for _, tt := range tests {
+		if got := entryKey(tt.word, tt.pos); got != tt.want {
+			t.Errorf("entryKey(%q, %q) = %q, want %q", tt.word, tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestFormatVocabLineRoundTrip(t *testing.T) {
+	inputs := []string{
+		"bank = 은행, 둑",
+		"conduct (n.) = 행동 \"Her conduct was admirable.\", 처신 #formal // 수능 빈출",
+	}
+	for _, input := range inputs {
+		pairs, _ := parseVocabLines(input)
+		if len(pairs) != 1 {
+			t.Fatalf("%q: got %d pairs", input, len(pairs))
+		}
+		again, _ := parseVocabLines(formatVocabLine(pairs[0]))
+		if !reflect.DeepEqual(again, pairs) {
+			t.Errorf("%q: formatVocabLine gives %q, read back as %#v", input, formatVocabLine(pairs[0]), again)
+		}
+	}
+}
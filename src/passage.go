@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+)
+
+// blankLetters label the blanks of a passage, (a) to (h).
+const blankLetters = "abcdefgh"
+
+var (
+	// blankAnswerRe reads one written answer of a passage, e.g. "(b) conduct".
+	blankAnswerRe = regexp.MustCompile(`\(([a-h])\)\s*([^()]+)`)
+	// blankRe finds the blanks in a passage line, e.g. "(a) _______".
+	blankRe = regexp.MustCompile(`\(([a-h])\)\s*_{2,}`)
+	// wordBankRe matches the [보기] line under a passage.
+	wordBankRe = regexp.MustCompile(`^\s*\[\s*보기\s*\]\s*:?\s*(.*)$`)
+)
+
+// blankLabel returns the label letter of the n-th blank (1-based).
+func blankLabel(n int) string {
+	if n < 1 || n > len(blankLetters) {
+		return "?"
+	}
+	return blankLetters[n-1 : n]
+}
+
+// blankAnswers splits a passage answer like "(a) bank (b) conduct" into the
+// answers in label order.
+func blankAnswers(answerText string) []string {
+	var answers []string
+	for _, m := range blankAnswerRe.FindAllStringSubmatch(answerText, -1) {
+		answers = append(answers, strings.Trim(strings.TrimSpace(m[2]), ",;"))
+	}
+	return answers
+}
+
+// passageBlanks returns the blank labels found in the question body, in order.
+func passageBlanks(q Question) []string {
+	var labels []string
+	for _, line := range q.Context {
+		for _, m := range blankRe.FindAllStringSubmatch(line, -1) {
+			labels = append(labels, m[1])
+		}
+	}
+	return labels
+}
+
+// wordBank returns the index of the [보기] line in q.Context and its words, or -1.
+func wordBank(q Question) (int, []string) {
+	for i, line := range q.Context {
+		if m := wordBankRe.FindStringSubmatch(line); m != nil {
+			var words []string
+			for _, w := range strings.FieldsFunc(m[1], func(r rune) bool { return r == '/' || r == ',' }) {
+				if w = strings.TrimSpace(w); w != "" {
+					words = append(words, w)
+				}
+			}
+			return i, words
+		}
+	}
+	return -1, nil
+}
+
+// validatePassage checks that the blanks, the answer key and the [보기] line
+// of a passage question agree.
+func validatePassage(q Question, add func(q Question, format string, args ...any)) {
+	answers := blankAnswers(q.AnswerText)
+	labels := passageBlanks(q)
+	if len(labels) != len(answers) {
+		add(q, "지문의 빈칸은 %d개인데 [정답]은 %d개입니다", len(labels), len(answers))
+	}
+	for i, l := range labels {
+		if l != blankLabel(i+1) {
+			add(q, "빈칸 (%s)의 순서가 맞지 않습니다", l)
+			break
+		}
+	}
+	if _, bank := wordBank(q); bank != nil {
+		for _, a := range answers {
+			if indexOfChoice(bank, a) < 0 {
+				add(q, "정답 '%s'이(가) [보기]에 없습니다", a)
+			}
+		}
+	}
+}
+
+// toBlankChoices replaces the [보기] line of a passage question with one line of
+// ①–⑤ choices per blank, drawn from the word bank, and rewrites the answer as
+// "(a) ② (b) ⑤ ...". A bank of fewer than five words is padded with other words
+// of pool, the vocabulary list. It reports false, leaving the question as it
+// is, if there is no bank or still not enough words.
+func toBlankChoices(q *Question, pool []string, rng *rand.Rand) bool {
+	at, bank := wordBank(*q)
+	answers := blankAnswers(q.AnswerText)
+	if at < 0 || len(answers) == 0 {
+		return false
+	}
+
+	var lines, key []string
+	for i, answer := range answers {
+		var others []string
+		for _, w := range bank {
+			if !strings.EqualFold(w, answer) {
+				others = append(others, w)
+			}
+		}
+		rng.Shuffle(len(others), func(a, b int) { others[a], others[b] = others[b], others[a] })
+		if len(others) < numChoices-1 {
+			var extra []string
+			for _, w := range pool {
+				if !strings.EqualFold(w, answer) && indexOfChoice(others, w) < 0 && indexOfChoice(extra, w) < 0 {
+					extra = append(extra, w)
+				}
+			}
+			rng.Shuffle(len(extra), func(a, b int) { extra[a], extra[b] = extra[b], extra[a] })
+			others = append(others, extra...)
+		}
+		if len(others) < numChoices-1 {
+			return false
+		}
+		choices := append([]string{answer}, others[:numChoices-1]...)
+		rng.Shuffle(len(choices), func(a, b int) { choices[a], choices[b] = choices[b], choices[a] })
+
+		var cells []string
+		for j, c := range choices {
+			cells = append(cells, fmt.Sprintf("%s %s", choiceMarks[j], c))
+			if c == answer {
+				key = append(key, fmt.Sprintf("(%s) %s", blankLabel(i+1), choiceMarks[j]))
+			}
+		}
+		lines = append(lines, fmt.Sprintf("(%s) %s", blankLabel(i+1), strings.Join(cells, "  ")))
+	}
+
+	context := append([]string{}, q.Context[:at]...)
+	context = append(context, lines...)
+	q.Context = append(context, q.Context[at+1:]...)
+	q.AnswerText = strings.Join(key, " ")
+	return true
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestToBlankChoices(t *testing.T) {
+	passage := "The (a) _______ was closed, so we (b) _______ home."
+	tests := []struct {
+		name string
+		bank string
+		pool []string
+		ok   bool
+	}{
+		{"full bank", "[보기] bank / ran / fair / bear / light / stone", nil, true},
+		{"padded from the list", "[보기] bank / ran / fair", []string{"bank", "ran", "fair", "Bear", "light", "stone"}, true},
+		{"list too short", "[보기] bank / ran", []string{"bank", "ran", "BANK", "fair"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Question{Number: 1, Context: []string{passage, tt.bank}, Answer: -1, AnswerText: "(a) bank (b) ran"}
+			before := q
+			before.Context = append([]string(nil), q.Context...)
+			if ok := toBlankChoices(&q, tt.pool, rand.New(rand.NewSource(3))); ok != tt.ok {
+				t.Fatalf("toBlankChoices = %v, want %v", ok, tt.ok)
+			}
+			if !tt.ok {
+				if strings.Join(q.Context, "\n") != strings.Join(before.Context, "\n") || q.AnswerText != before.AnswerText {
+					t.Errorf("question changed: %+v", q)
+				}
+				return
+			}
+			if len(q.Context) != 3 || q.Context[0] != passage {
+				t.Fatalf("context = %q", q.Context)
+			}
+			answers := strings.Fields(q.AnswerText)
+			for i, want := range []string{"bank", "ran"} {
+				line := q.Context[i+1]
+				label := "(" + blankLabel(i+1) + ")"
+				if !strings.HasPrefix(line, label+" ") || answers[2*i] != label {
+					t.Fatalf("blank %s: line %q, answer %q", label, line, q.AnswerText)
+				}
+				var choices []string
+				for _, c := range choiceSplitRe.Split(strings.TrimPrefix(line, label), -1)[1:] {
+					choices = append(choices, strings.TrimSpace(c))
+				}
+				if len(choices) != numChoices || hasDuplicateChoice(choices) {
+					t.Errorf("blank %s: choices %q", label, choices)
+				}
+				if got := choices[choiceIndex(answers[2*i+1])]; got != want {
+					t.Errorf("blank %s: answer %s is %q, want %q", label, answers[2*i+1], got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,678 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// A4 page layout, in points.
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 42.5 // 15 mm
+	pdfColumnGap  = 22.7 // 8 mm
+	pdfFontSize   = 10.5
+	pdfTitleSize  = 16
+	pdfLeading    = 1.5 // line height relative to the font size
+)
+
+// pdfFontPaths are the Korean TrueType fonts tried when print.font is not set.
+// Paths starting with "~" are in the user's home directory.
+var pdfFontPaths = []string{
+	`C:\Windows\Fonts\malgun.ttf`,
+	"~/Library/Fonts/NanumGothic.ttf",
+	"/Library/Fonts/NanumGothic.ttf",
+	"/System/Library/Fonts/AppleSDGothicNeo.ttc",
+	"~/.local/share/fonts/NanumGothic.ttf",
+	"/usr/share/fonts/truetype/nanum/NanumGothic.ttf",
+	"/usr/share/fonts/nanum/NanumGothic.ttf",
+}
+
+// pdfBaseText is what every PDF needs from its font besides the text itself:
+// Hangul and the choice marks.
+const pdfBaseText = "가①②③④⑤"
+
+// findPDFFont loads the configured font, or the first system font that has a
+// glyph for every character of text. A configured font without them is an
+// error rather than a PDF of empty boxes.
+func findPDFFont(path, text string) (*ttfFont, error) {
+	text += pdfBaseText
+	if path != "" {
+		f, err := loadTTF(path)
+		if err != nil {
+			return nil, err
+		}
+		if missing := f.missing(text); len(missing) > 0 {
+			list := string(missing[:min(len(missing), 10)])
+			if len(missing) > 10 {
+				list += fmt.Sprintf(" 외 %d자", len(missing)-10)
+			}
+			return nil, fmt.Errorf("%s: 글꼴에 없는 문자가 있습니다 (%s). 한글 TrueType 글꼴을 지정하세요", path, list)
+		}
+		return f, nil
+	}
+	home, _ := os.UserHomeDir()
+	for _, p := range pdfFontPaths {
+		if strings.HasPrefix(p, "~/") {
+			if home == "" {
+				continue
+			}
+			p = filepath.Join(home, p[2:])
+		}
+		if f, err := loadTTF(p); err == nil && len(f.missing(text)) == 0 {
+			return f, nil
+		}
+	}
+	return nil, errors.New("PDF에 넣을 한글 TrueType 글꼴을 찾을 수 없습니다. api.json의 print.font에 .ttf 파일 경로를 지정하세요")
+}
+
+// pdfText is a run of text on a line; x is relative to the column.
+type pdfText struct {
+	x         float64
+	text      string
+	size      float64
+	bold      bool
+	underline bool
+}
+
+// pdfLine is one line of a block. A boxed line draws the sides of a box from
+// boxLeft to the right edge of the column, and its top or bottom edge.
+type pdfLine struct {
+	height         float64
+	items          []pdfText
+	boxed          bool
+	boxLeft        float64
+	boxTop, boxEnd bool
+}
+
+// add appends text at x, joining it to the previous run of the same style.
+func (ln *pdfLine) add(t pdfText, sep string) {
+	if n := len(ln.items); n > 0 {
+		last := &ln.items[n-1]
+		if last.bold == t.bold && last.underline == t.underline && last.size == t.size {
+			last.text += sep + t.text
+			return
+		}
+	}
+	ln.items = append(ln.items, t)
+}
+
+// pdfDoc collects the pages and the glyphs they use.
+type pdfDoc struct {
+	font  *ttfFont
+	used  map[uint16]rune // glyph -> the character it was used for
+	pages []*bytes.Buffer
+}
+
+// renderPDF lays the text out like renderHTML: A4 pages with two columns, a
+// header with the title and name fields, and a new page for every form.
+// Questions move to the next column whole unless they are taller than one.
+// The glyphs used are embedded, so the file prints the same everywhere.
+func renderPDF(text string, cfg PrintConfig) ([]byte, error) {
+	font, err := findPDFFont(cfg.Font, text+cfg.title()+strings.Join(cfg.fields(), ""))
+	if err != nil {
+		return nil, err
+	}
+	d := &pdfDoc{font: font, used: make(map[uint16]rune)}
+	l := &pdfLayout{doc: d, columns: 2}
+	sections := printSections(text)
+
+	for i, s := range sections {
+		key := s.isKey()
+		switch {
+		case !startsPage(sections, i):
+		case key:
+			l.newPage(cfg.title()+" 정답", nil)
+		case s.Form != "":
+			l.newPage(fmt.Sprintf("%s (%s형)", cfg.title(), s.Form), cfg.fields())
+		default:
+			l.newPage(cfg.title(), cfg.fields())
+		}
+		for _, q := range s.Questions {
+			l.place(l.questionLines(q), pdfFontSize)
+		}
+		switch {
+		case key && s.Form != "":
+			l.pending = l.headingLines(s.Form + "형")
+		case !key && len(s.Answers) > 0:
+			l.pending = l.headingLines("[정답]")
+		}
+		for _, a := range s.Answers {
+			l.place(d.wrap([]textSpan{{Text: a}}, pdfFontSize, l.colWidth(), 0, l.numberIndent(), false), 0)
+		}
+		if len(s.Table) > 0 {
+			if l.tableWidth(s.Table) > l.colWidth() {
+				// Many forms make the table too wide for a column; it gets a
+				// page of its own.
+				l.startPage()
+				l.columns = 1
+			}
+			l.pending = append(l.pending, l.headingLines(tableHeader)...)
+			for r, row := range s.Table {
+				l.place([]pdfLine{l.tableLine(s.Table, row, r == 0)}, 0)
+			}
+		}
+		l.pending = nil
+	}
+	l.finishPage()
+	return d.write(cfg.title()), nil
+}
+
+// pdfLayout places blocks of lines top to bottom, column by column.
+type pdfLayout struct {
+	doc     *pdfDoc
+	columns int
+	page    *bytes.Buffer
+	col     int
+	top, y  float64 // top of the columns and the next free line, from the top of the page
+	// pending lines are placed with the next block, so a heading is never
+	// left alone at the bottom of a column.
+	pending []pdfLine
+}
+
+func (l *pdfLayout) colWidth() float64 {
+	return (pdfPageWidth - 2*pdfMargin - float64(l.columns-1)*pdfColumnGap) / float64(l.columns)
+}
+
+func (l *pdfLayout) colX() float64 {
+	return pdfMargin + float64(l.col)*(l.colWidth()+pdfColumnGap)
+}
+
+// newPage starts a page with the title, the fields if there are any, and a rule.
+func (l *pdfLayout) newPage(title string, fields []string) {
+	l.startPage()
+	d := l.doc
+	y := pdfMargin + pdfTitleSize
+	d.show(l.page, (pdfPageWidth-d.font.width(title, pdfTitleSize))/2, y, pdfText{text: title, size: pdfTitleSize, bold: true})
+
+	if len(fields) > 0 {
+		const size, blank, gap = 10, 56, 12
+		total := 0.0
+		for _, f := range fields {
+			total += d.font.width(f+" ", size) + blank + gap
+		}
+		x := pdfPageWidth - pdfMargin - total + gap
+		y += pdfTitleSize * 1.4
+		for _, f := range fields {
+			d.show(l.page, x, y, pdfText{text: f, size: size})
+			x += d.font.width(f+" ", size)
+			d.rule(l.page, x, y+2, x+blank, y+2, 0.5)
+			x += blank + gap
+		}
+	}
+	y += 8
+	d.rule(l.page, pdfMargin, y, pdfPageWidth-pdfMargin, y, 1.2)
+	l.top, l.y = y+12, y+12
+}
+
+// startPage begins an empty page and finishes the one before.
+func (l *pdfLayout) startPage() {
+	if l.page != nil {
+		l.finishPage()
+	}
+	l.page = new(bytes.Buffer)
+	l.doc.pages = append(l.doc.pages, l.page)
+	l.col = 0
+	l.top, l.y = pdfMargin, pdfMargin
+}
+
+// finishPage draws the column rules and the page number.
+func (l *pdfLayout) finishPage() {
+	if l.page == nil {
+		return
+	}
+	d := l.doc
+	for c := 1; c < l.columns; c++ {
+		x := pdfMargin + float64(c)*(l.colWidth()+pdfColumnGap) - pdfColumnGap/2
+		d.rule(l.page, x, l.top, x, pdfPageHeight-pdfMargin, 0.4)
+	}
+	number := fmt.Sprintf("- %d -", len(d.pages))
+	d.show(l.page, (pdfPageWidth-d.font.width(number, 9))/2, pdfPageHeight-pdfMargin/2, pdfText{text: number, size: 9})
+	l.page = nil
+}
+
+// nextColumn moves to the top of the next column, on a new page after the last.
+func (l *pdfLayout) nextColumn() {
+	if l.col+1 < l.columns {
+		l.col++
+		l.y = l.top
+		return
+	}
+	l.startPage()
+}
+
+// place draws lines as a block followed by gap. A block that does not fit
+// moves to the next column; one taller than a column is split between lines.
+func (l *pdfLayout) place(lines []pdfLine, gap float64) {
+	if l.page == nil {
+		l.startPage()
+	}
+	lines = append(l.pending, lines...)
+	l.pending = nil
+	bottom := pdfPageHeight - pdfMargin
+	height := 0.0
+	for _, ln := range lines {
+		height += ln.height
+	}
+	if l.y+height > bottom && l.y > l.top && height <= bottom-l.top {
+		l.nextColumn()
+	}
+	for _, ln := range lines {
+		if l.y+ln.height > bottom && l.y > l.top {
+			l.nextColumn()
+		}
+		l.drawLine(ln)
+		l.y += ln.height
+	}
+	l.y += gap
+}
+
+func (l *pdfLayout) drawLine(ln pdfLine) {
+	d, x := l.doc, l.colX()
+	baseline := l.y + ln.height*0.72
+	for _, t := range ln.items {
+		d.show(l.page, x+t.x, baseline, t)
+	}
+	if ln.boxed {
+		left, right, bottom := x+ln.boxLeft, x+l.colWidth(), l.y+ln.height
+		d.rule(l.page, left, l.y, left, bottom, 0.6)
+		d.rule(l.page, right, l.y, right, bottom, 0.6)
+		if ln.boxTop {
+			d.rule(l.page, left, l.y, right, l.y, 0.6)
+		}
+		if ln.boxEnd {
+			d.rule(l.page, left, bottom, right, bottom, 0.6)
+		}
+	}
+}
+
+// questionLines lays out a question: the number and prompt with a hanging
+// indent, the context, then the choices side by side when they fit.
+func (l *pdfLayout) questionLines(q printQuestion) []pdfLine {
+	d, size, width := l.doc, pdfFontSize, l.colWidth()
+	indent := l.numberIndent()
+	var lines []pdfLine
+	if q.Raw != nil {
+		for _, raw := range q.Raw {
+			lines = append(lines, d.wrap([]textSpan{{Text: raw}}, size, width, 0, indent, false)...)
+		}
+		return lines
+	}
+
+	lines = d.wrap([]textSpan{{Text: q.Prompt}}, size, width, indent, indent, false)
+	number := pdfText{text: fmt.Sprintf("%d.", q.Number), size: size, bold: true}
+	lines[0].items = append([]pdfText{number}, lines[0].items...)
+
+	if q.Matching != nil {
+		// The meanings line up in a column after the widest word.
+		right := 0.0
+		for _, row := range q.Matching {
+			right = max(right, d.font.width(row[0], size))
+		}
+		right += indent + size*1.5
+		for _, row := range q.Matching {
+			mark, _, _ := strings.Cut(row[1], " ")
+			meaning := d.wrap([]textSpan{{Text: row[1]}}, size, width, right, right+d.font.width(mark+" ", size), false)
+			meaning[0].items = append([]pdfText{{x: indent, text: row[0], size: size}}, meaning[0].items...)
+			lines = append(lines, meaning...)
+		}
+		return lines
+	}
+	for _, c := range q.Context {
+		if !wordBankRe.MatchString(c) {
+			lines = append(lines, d.wrap(markedSpans(c), size, width, indent, indent, false)...)
+			continue
+		}
+		const pad = 4
+		bank := d.wrap([]textSpan{{Text: c}}, size, width-pad, indent+pad, indent+pad, false)
+		for i := range bank {
+			bank[i].boxed, bank[i].boxLeft = true, indent
+		}
+		bank[0].boxTop, bank[len(bank)-1].boxEnd = true, true
+		lines = append(lines, bank...)
+	}
+
+	if len(q.Choices) == 0 || q.Underlined {
+		return lines
+	}
+	avail := width - indent
+	perRow := choicesPerRow(q.Choices, avail, func(s string) float64 { return d.font.width(s, size) + size })
+	if perRow == 1 {
+		for i, c := range q.Choices {
+			mark := choiceMarks[i%len(choiceMarks)] + " "
+			lines = append(lines, d.wrap([]textSpan{{Text: mark + c}}, size, width, indent, indent+d.font.width(mark, size), false)...)
+		}
+		return lines
+	}
+	cell := avail / float64(perRow)
+	for start := 0; start < len(q.Choices); start += perRow {
+		ln := pdfLine{height: size * pdfLeading}
+		for i := start; i < min(start+perRow, len(q.Choices)); i++ {
+			x := indent + float64(i-start)*cell
+			ln.items = append(ln.items, pdfText{x: x, text: choiceMarks[i%len(choiceMarks)] + " " + q.Choices[i], size: size})
+		}
+		lines = append(lines, ln)
+	}
+	return lines
+}
+
+// numberIndent is where the text of a question starts after its number.
+func (l *pdfLayout) numberIndent() float64 {
+	return l.doc.font.width("00.", pdfFontSize) + pdfFontSize*0.6
+}
+
+func (l *pdfLayout) headingLines(text string) []pdfLine {
+	size := pdfFontSize + 1
+	lines := l.doc.wrap([]textSpan{{Text: text}}, size, l.colWidth(), 0, 0, true)
+	lines[0].height += size * 0.5
+	return lines
+}
+
+// tableCell is the width of one form's column of the cross-reference table.
+func (l *pdfLayout) tableCell(table [][]string) float64 {
+	cell := 0.0
+	for _, row := range table {
+		for _, c := range row[:len(row)-1] {
+			cell = max(cell, l.doc.font.width(c, pdfFontSize))
+		}
+	}
+	return cell + pdfFontSize
+}
+
+// tableWidth is the width the table needs with a short list of words.
+func (l *pdfLayout) tableWidth(table [][]string) float64 {
+	return float64(len(table[0])-1)*l.tableCell(table) + l.doc.font.width("vocabulary", pdfFontSize)
+}
+
+func (l *pdfLayout) tableLine(table [][]string, row []string, header bool) pdfLine {
+	cell := l.tableCell(table)
+	ln := pdfLine{height: pdfFontSize * pdfLeading}
+	for i, c := range row {
+		ln.items = append(ln.items, pdfText{x: float64(i) * cell, text: c, size: pdfFontSize, bold: header})
+	}
+	return ln
+}
+
+// wrap breaks spans into lines no wider than width. The first line starts at
+// first and the others at indent. Spans that touch without a space break together.
+func (d *pdfDoc) wrap(spans []textSpan, size, width, first, indent float64, bold bool) []pdfLine {
+	var words [][]textSpan
+	glued := false
+	for _, s := range spans {
+		for i, part := range strings.Split(strings.ReplaceAll(s.Text, "\t", " "), " ") {
+			if i > 0 {
+				glued = false
+			}
+			if part == "" {
+				continue
+			}
+			piece := textSpan{Text: part, Underline: s.Underline}
+			if glued {
+				words[len(words)-1] = append(words[len(words)-1], piece)
+			} else {
+				words = append(words, []textSpan{piece})
+			}
+			glued = true
+		}
+	}
+
+	space := d.font.width(" ", size)
+	lines := []pdfLine{{height: size * pdfLeading}}
+	x := first
+	for _, word := range d.breakLongWords(words, size, width-indent) {
+		ww := 0.0
+		for _, p := range word {
+			ww += d.font.width(p.Text, size)
+		}
+		ln := &lines[len(lines)-1]
+		sep := ""
+		switch {
+		case len(ln.items) > 0 && x+space+ww > width:
+			lines = append(lines, pdfLine{height: size * pdfLeading})
+			ln, x = &lines[len(lines)-1], indent
+		case len(ln.items) > 0:
+			x += space
+			sep = " "
+		}
+		for _, p := range word {
+			ln.add(pdfText{x: x, text: p.Text, size: size, bold: bold, underline: p.Underline}, sep)
+			x += d.font.width(p.Text, size)
+			sep = ""
+		}
+	}
+	return lines
+}
+
+// breakLongWords splits words wider than width between characters.
+func (d *pdfDoc) breakLongWords(words [][]textSpan, size, width float64) [][]textSpan {
+	var out [][]textSpan
+	for _, word := range words {
+		var cur []textSpan
+		w := 0.0
+		for _, p := range word {
+			for _, r := range p.Text {
+				rw := d.font.width(string(r), size)
+				if w+rw > width && w > 0 {
+					out = append(out, cur)
+					cur, w = nil, 0
+				}
+				if n := len(cur); n > 0 && cur[n-1].Underline == p.Underline {
+					cur[n-1].Text += string(r)
+				} else {
+					cur = append(cur, textSpan{Text: string(r), Underline: p.Underline})
+				}
+				w += rw
+			}
+		}
+		out = append(out, cur)
+	}
+	return out
+}
+
+// show draws t with its baseline at y from the top of the page. Bold is drawn
+// by stroking the outline as well, since only one font is embedded.
+func (d *pdfDoc) show(page *bytes.Buffer, x, y float64, t pdfText) {
+	var hex strings.Builder
+	for _, r := range t.text {
+		gid, _ := d.font.glyph(r)
+		if _, ok := d.used[gid]; !ok {
+			d.used[gid] = r
+		}
+		fmt.Fprintf(&hex, "%04X", gid)
+	}
+	if t.bold {
+		page.WriteString("q 0.3 w 2 Tr\n")
+	}
+	fmt.Fprintf(page, "BT /F1 %s Tf 1 0 0 1 %s %s Tm <%s> Tj ET\n", pdfNum(t.size), pdfNum(x), pdfNum(pdfPageHeight-y), hex.String())
+	if t.bold {
+		page.WriteString("Q\n")
+	}
+	if t.underline {
+		under := y + t.size*0.15
+		d.rule(page, x, under, x+d.font.width(t.text, t.size), under, 0.5)
+	}
+}
+
+// rule draws a line between two points measured from the top of the page.
+func (d *pdfDoc) rule(page *bytes.Buffer, x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(page, "%s w %s %s m %s %s l S\n", pdfNum(width),
+		pdfNum(x1), pdfNum(pdfPageHeight-y1), pdfNum(x2), pdfNum(pdfPageHeight-y2))
+}
+
+func pdfNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// write assembles the PDF file. The font is embedded as a CID-keyed TrueType
+// font with glyph ids as character codes, plus a ToUnicode map so the text can
+// be searched and copied.
+func (d *pdfDoc) write(title string) []byte {
+	const (
+		catalogID = iota + 1
+		pagesID
+		fontID
+		cidFontID
+		descriptorID
+		fontFileID
+		toUnicodeID
+		infoID
+		firstPageID
+	)
+	f := d.font
+	gids := make([]uint16, 0, len(d.used))
+	used := make(map[uint16]bool)
+	for g := range d.used {
+		gids = append(gids, g)
+		used[g] = true
+	}
+	sort.Slice(gids, func(i, j int) bool { return gids[i] < gids[j] })
+	baseFont := subsetTag(gids) + "+" + f.name
+
+	var w pdfWriter
+	w.object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPageID+2*i))
+	}
+	w.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	w.object(fontID, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		baseFont, cidFontID, toUnicodeID))
+
+	var widths strings.Builder
+	for i, g := range gids {
+		if i == 0 || gids[i-1] != g-1 {
+			if i > 0 {
+				widths.WriteString("] ")
+			}
+			fmt.Fprintf(&widths, "%d [", g)
+		} else {
+			widths.WriteString(" ")
+		}
+		fmt.Fprintf(&widths, "%d", f.scale(f.advances[g]))
+	}
+	if len(gids) > 0 {
+		widths.WriteString("]")
+	}
+	w.object(cidFontID, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /DW 1000 /W [%s] /CIDToGIDMap /Identity >>",
+		baseFont, descriptorID, widths.String()))
+	w.object(descriptorID, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		baseFont, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.capHeight), fontFileID))
+	fontFile := f.subset(used)
+	w.stream(fontFileID, fmt.Sprintf("/Length1 %d", len(fontFile)), fontFile)
+	w.stream(toUnicodeID, "", d.toUnicode(gids))
+	w.object(infoID, fmt.Sprintf("<< /Title %s /Producer (vocab-maker) >>", pdfTextString(title)))
+
+	for i, page := range d.pages {
+		pageID := firstPageID + 2*i
+		w.object(pageID, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesID, pdfNum(pdfPageWidth), pdfNum(pdfPageHeight), fontID, pageID+1))
+		w.stream(pageID+1, "", page.Bytes())
+	}
+	return w.finish(catalogID, infoID)
+}
+
+// toUnicode returns the CMap mapping the used glyphs back to their characters.
+func (d *pdfDoc) toUnicode(gids []uint16) []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	var entries []string
+	for _, g := range gids {
+		if g == 0 {
+			continue
+		}
+		var hex strings.Builder
+		for _, u := range utf16.Encode([]rune{d.used[g]}) {
+			fmt.Fprintf(&hex, "%04X", u)
+		}
+		entries = append(entries, fmt.Sprintf("<%04X> <%s>", g, hex.String()))
+	}
+	// A bfchar section holds at most 100 entries.
+	for start := 0; start < len(entries); start += 100 {
+		chunk := entries[start:min(start+100, len(entries))]
+		fmt.Fprintf(&b, "%d beginbfchar\n%s\nendbfchar\n", len(chunk), strings.Join(chunk, "\n"))
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMapResource defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// subsetTag is the six-letter prefix that marks an embedded subset font.
+func subsetTag(gids []uint16) string {
+	var b bytes.Buffer
+	for _, g := range gids {
+		b.WriteByte(byte(g >> 8))
+		b.WriteByte(byte(g))
+	}
+	sum := crc32.ChecksumIEEE(b.Bytes())
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(sum%26)
+		sum /= 26
+	}
+	return string(tag)
+}
+
+// pdfTextString encodes s as a UTF-16 text string.
+func pdfTextString(s string) string {
+	var hex strings.Builder
+	hex.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&hex, "%04X", u)
+	}
+	return hex.String() + ">"
+}
+
+// pdfWriter writes numbered objects and the cross-reference table.
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (w *pdfWriter) object(id int, body string) {
+	if w.offsets == nil {
+		w.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[id] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes data compressed, with extra entries added to its dictionary.
+func (w *pdfWriter) stream(id int, extra string, data []byte) {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(data)
+	zw.Close()
+	dict := fmt.Sprintf("<< /Length %d /Filter /FlateDecode", z.Len())
+	if extra != "" {
+		dict += " " + extra
+	}
+	w.object(id, dict+" >>\nstream\n"+z.String()+"\nendstream")
+}
+
+func (w *pdfWriter) finish(rootID, infoID int) []byte {
+	size := 0
+	for id := range w.offsets {
+		size = max(size, id)
+	}
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", size+1)
+	for id := 1; id <= size; id++ {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", w.offsets[id])
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", size+1, rootID, infoID, xref)
+	return w.buf.Bytes()
+}
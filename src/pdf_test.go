@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pdfHeadings are the strings renderPDF adds to a paper.
+const pdfHeadings = "영어 어휘 평가 정답 반번호이름날짜 (A형) [정답] " + tableHeader + pdfBaseText
+
+var (
+	startXrefRe = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	xrefEntryRe = regexp.MustCompile(`^(\d{10}) (\d{5}) ([nf]) \n$`)
+	pageCountRe = regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`)
+)
+
+// checkXref verifies that the cross-reference table of a PDF points at its objects.
+func checkXref(t *testing.T, pdf []byte) {
+	t.Helper()
+	m := startXrefRe.FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("no startxref at the end of the file")
+	}
+	at, _ := strconv.Atoi(string(m[1]))
+	if at >= len(pdf) || !bytes.HasPrefix(pdf[at:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point at the xref table", at)
+	}
+	lines := strings.SplitAfter(string(pdf[at:]), "\n")
+	size, err := strconv.Atoi(strings.Fields(lines[1])[1])
+	if err != nil {
+		t.Fatalf("xref subsection %q", lines[1])
+	}
+	for id := 0; id < size; id++ {
+		e := xrefEntryRe.FindStringSubmatch(lines[2+id])
+		if e == nil {
+			t.Fatalf("xref entry %d is %q", id, lines[2+id])
+		}
+		if id == 0 {
+			continue
+		}
+		off, _ := strconv.Atoi(e[1])
+		if want := strconv.Itoa(id) + " 0 obj\n"; !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Errorf("object %d: xref offset %d points at %q", id, off, pdf[off:min(off+20, len(pdf))])
+		}
+	}
+	if !strings.Contains(string(pdf[at:]), "trailer\n<< /Size "+strconv.Itoa(size)+" /Root 1 0 R") {
+		t.Error("trailer does not match the xref table")
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	questions := testPaper(4)
+	forms := makeForms(questions, 2, nil, 1)
+	paper, key := splitPaper(formatForms(forms))
+	tests := []struct {
+		name  string
+		text  string
+		pages int
+	}{
+		{"paper", strings.TrimSuffix(formatQuestions(questions), formatAnswerKey(questions)), 1},
+		{"paper with key", formatQuestions(questions), 1},
+		{"forms", paper, 2},
+		{"keys of forms share a page", key, 1},
+		{"matching table", "1. 단어와 뜻을 연결하시오.\n(1) bank     ⓐ 은행\n(2) conduct  ⓑ 행동\n\n[정답]\n1. (1) ⓐ (2) ⓑ\n", 1},
+		{"text that is not a question", "아무 말\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			font := writeTestFont(t, tt.text+pdfHeadings)
+			pdf, err := renderPDF(tt.text, PrintConfig{Font: font})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(pdf, []byte("%PDF-1.7\n")) {
+				t.Errorf("file starts with %q", pdf[:min(len(pdf), 10)])
+			}
+			checkXref(t, pdf)
+			m := pageCountRe.FindSubmatch(pdf)
+			if m == nil {
+				t.Fatal("no page tree")
+			}
+			if pages, _ := strconv.Atoi(string(m[1])); pages != tt.pages {
+				t.Errorf("%d pages, want %d", pages, tt.pages)
+			}
+			if !bytes.Contains(pdf, []byte("/BaseFont /")) || !bytes.Contains(pdf, []byte("+Test-Font ")) {
+				t.Error("the font is not embedded as a subset")
+			}
+		})
+	}
+}
+
+func TestFindPDFFont(t *testing.T) {
+	tests := []struct {
+		name, chars, text string
+		wantErr           string
+	}{
+		{"covers the text", "bank은행" + pdfBaseText, "bank 은행", ""},
+		{"missing characters", "bank" + pdfBaseText, "bank 은행", "글꼴에 없는 문자가 있습니다 (은행)"},
+		{"many missing characters", pdfBaseText, "abcdefghijkl", "(abcdefghij 외 2자)"},
+		{"missing choice marks", "bank은행가", "bank 은행", "(①②③④⑤)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := findPDFFont(writeTestFont(t, tt.chars), tt.text)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPDFTextString(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", "<FEFF>"},
+		{"A", "<FEFF0041>"},
+		{"정답", "<FEFFC815B2F5>"},
+		{"😀", "<FEFFD83DDE00>"},
+	}
+	for _, tt := range tests {
+		if got := pdfTextString(tt.in); got != tt.want {
+			t.Errorf("pdfTextString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultChunkSize   = 25
+	defaultConcurrency = 3
+)
+
+// GenerationJob describes one generation run over a vocabulary list.
+type GenerationJob struct {
+	Model        string
+	QuestionType string
+	NumSentences int
+	Vocab        []VocabPair
+	// Learner sets the difficulty and target grade of the prompts.
+	Learner LearnerProfile
+	// Mix makes a test of several question types; QuestionType is ignored then.
+	Mix []MixPart
+	// DistractorPool is where offline types draw wrong choices from; nil means Vocab.
+	DistractorPool []VocabPair
+
+	// ChunkSize is the number of words sent per request; Concurrency bounds the
+	// number of requests in flight at once.
+	ChunkSize   int
+	Concurrency int
+
+	// Structured requests JSON-schema output (see questionSchema) instead of free text.
+	Structured bool
+
+	// MaxRegenerations is how many times words whose questions fail validation
+	// are sent again. Zero only validates.
+	MaxRegenerations int
+
+	// AnswerWeights is the target share of ①–⑤ as correct answers (nil: 20% each).
+	// AnswerSeed makes the reshuffle reproducible; zero picks a fresh seed.
+	AnswerWeights []int
+	AnswerSeed    int64
+
+	// OnDelta receives streamed text. It is only used for free-text output when
+	// the list fits in a single chunk, since parallel chunks would interleave.
+	OnDelta    func(delta string)
+	OnRetry    func(attempt, maxRetries int, wait time.Duration, err *GenerationError)
+	OnProgress func(done, total int)
+	// OnRegenerate is called before each regeneration round with the words being re-requested.
+	OnRegenerate func(round int, words []VocabPair)
+}
+
+type promptPair struct {
+	system, user string
+}
+
+// GenerationResult is the merged output of a job. ParseErr lists the blocks that
+// could not be read as questions; they are left out of Text once the answers
+// are balanced. Issues are the validation problems left after regeneration.
+type GenerationResult struct {
+	Text      string
+	Questions []Question
+	ParseErr  error
+	Issues    []ValidationIssue
+	// Seed is the seed the answer positions were balanced with, or 0 if they were not.
+	Seed int64
+}
+
+// chunkVocab splits the list into consecutive chunks of at most size words.
+func chunkVocab(vocab []VocabPair, size int) [][]VocabPair {
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	var chunks [][]VocabPair
+	for start := 0; start < len(vocab); start += size {
+		end := start + size
+		if end > len(vocab) {
+			end = len(vocab)
+		}
+		chunks = append(chunks, vocab[start:end])
+	}
+	return chunks
+}
+
+// jobPrompts returns the prompts of every chunk of the job, in order. Local
+// question types have none.
+func jobPrompts(job GenerationJob) ([]promptPair, error) {
+	if len(job.Mix) > 0 {
+		var prompts []promptPair
+		for i, group := range assignMixWords(job.Vocab, job.Mix) {
+			partJob := job
+			partJob.Mix, partJob.QuestionType, partJob.Vocab = nil, job.Mix[i].QuestionType, group
+			part, err := jobPrompts(partJob)
+			if err != nil {
+				return nil, err
+			}
+			prompts = append(prompts, part...)
+		}
+		return prompts, nil
+	}
+	qt, _ := findQuestionType(job.QuestionType)
+	if qt.Local != nil {
+		return nil, nil
+	}
+	size := job.ChunkSize
+	if qt.Passage > 0 {
+		job.Structured = false
+		// End every chunk on a passage boundary so no passage is cut short.
+		if size <= 0 {
+			size = defaultChunkSize
+		}
+		size = max(size-size%qt.Passage, qt.Passage)
+	}
+	var prompts []promptPair
+	for _, chunk := range chunkVocab(job.Vocab, size) {
+		system, user, err := buildPrompts(chunk, job.QuestionType, job.NumSentences, job.Learner, job.Structured)
+		if err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, promptPair{system: system, user: user})
+	}
+	return prompts, nil
+}
+
+// runGeneration generates the paper, validates it and re-requests only the words
+// whose questions fail validation, up to job.MaxRegenerations times.
+func runGeneration(ctx context.Context, gen Generator, job GenerationJob) (GenerationResult, error) {
+	if len(job.Mix) > 0 {
+		return runMixedGeneration(ctx, gen, job)
+	}
+	qt, _ := findQuestionType(job.QuestionType)
+	if qt.Local != nil {
+		return runLocalGeneration(job, qt)
+	}
+	if qt.Passage > 0 {
+		// questionSchema has no room for blanks; passages always use the text layout.
+		job.Structured = false
+	}
+	result, err := generateOnce(ctx, gen, job)
+	if err != nil {
+		return result, err
+	}
+	result.Issues = validateQuestions(result.Questions, job.Vocab, job.QuestionType)
+
+	for round := 1; round <= job.MaxRegenerations && len(result.Issues) > 0; round++ {
+		words := failingWords(result.Issues, result.Questions, job.Vocab)
+		if len(words) == 0 {
+			break
+		}
+		if job.OnRegenerate != nil {
+			job.OnRegenerate(round, words)
+		}
+		retryJob := job
+		retryJob.Vocab = words
+		retryJob.OnDelta = nil
+		retryJob.OnProgress = nil
+		regen, err := generateOnce(ctx, gen, retryJob)
+		if err != nil {
+			// Keep the paper we have; the remaining issues are reported instead.
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			break
+		}
+		result.Questions = replaceWordQuestions(result.Questions, regen.Questions, words)
+		result.Text = formatQuestions(result.Questions)
+		// Malformed blocks of the first pass are gone from the reformatted text.
+		result.ParseErr = regen.ParseErr
+		result.Issues = validateQuestions(result.Questions, job.Vocab, job.QuestionType)
+	}
+
+	// Reformatting drops malformed blocks from the text; ParseErr still reports them.
+	if len(result.Questions) > 0 {
+		result.Seed = job.AnswerSeed
+		if result.Seed == 0 {
+			result.Seed = time.Now().UnixNano()
+		}
+		balanceAnswers(result.Questions, job.AnswerWeights, result.Seed)
+		var unconverted []Question
+		if qt.BlankChoices {
+			rng := rand.New(rand.NewSource(result.Seed))
+			var pool []string
+			for _, v := range job.Vocab {
+				pool = append(pool, v.Word)
+			}
+			for i := range result.Questions {
+				if !toBlankChoices(&result.Questions[i], pool, rng) {
+					unconverted = append(unconverted, result.Questions[i])
+				}
+			}
+		}
+		result.Text = formatQuestions(result.Questions)
+		result.Issues = validateQuestions(result.Questions, job.Vocab, job.QuestionType)
+		for _, q := range unconverted {
+			result.Issues = append(result.Issues, ValidationIssue{Number: q.Number, Word: q.Word, POS: q.POS, Reason: fmt.Sprintf("빈칸별 선택지를 만들 단어가 부족해 [보기] 형태로 남았습니다 (%d개 필요)", numChoices)})
+		}
+	}
+	setQuestionType(result.Questions, qt.ID)
+	return result, nil
+}
+
+func setQuestionType(questions []Question, id string) {
+	for i := range questions {
+		questions[i].Type = id
+	}
+}
+
+// generateOnce sends every chunk of the job through a bounded worker pool and
+// merges the results into one paper with continuous numbering and a single [정답] section.
+func generateOnce(ctx context.Context, gen Generator, job GenerationJob) (GenerationResult, error) {
+	outputs, err := generateChunks(ctx, gen, job)
+	if err != nil {
+		return GenerationResult{}, err
+	}
+
+	if job.Structured {
+		var questions []Question
+		var errs []error
+		for i, out := range outputs {
+			qs, err := decodeStructuredQuestions(out)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%d번째 묶음: %w", i+1, err))
+			}
+			questions = append(questions, qs...)
+		}
+		renumberQuestions(questions)
+		attachSources(questions, job.Vocab)
+		return GenerationResult{Text: formatQuestions(questions), Questions: questions, ParseErr: errors.Join(errs...)}, nil
+	}
+
+	text := outputs[0]
+	if len(outputs) > 1 {
+		text = mergeChunkOutputs(outputs)
+	}
+	questions, parseErr := parseQuestions(text)
+	attachSources(questions, job.Vocab)
+	return GenerationResult{Text: text, Questions: questions, ParseErr: parseErr}, nil
+}
+
+// renumberQuestions numbers questions consecutively from 1.
+func renumberQuestions(questions []Question) {
+	for i := range questions {
+		questions[i].Number = i + 1
+	}
+}
+
+// generateChunks returns the raw model output of every chunk of the job, in order.
+func generateChunks(ctx context.Context, gen Generator, job GenerationJob) ([]string, error) {
+	prompts, err := jobPrompts(job)
+	if err != nil {
+		return nil, err
+	}
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("생성할 단어가 없습니다. 'word = meaning' 형식을 확인하세요")
+	}
+	var schema *JSONSchema
+	if job.Structured {
+		schema = questionSchema
+	}
+
+	if len(prompts) == 1 {
+		req := GenerateRequest{
+			Model:        job.Model,
+			SystemPrompt: prompts[0].system,
+			UserPrompt:   prompts[0].user,
+			Schema:       schema,
+			OnRetry:      job.OnRetry,
+		}
+		if !job.Structured {
+			req.OnDelta = job.OnDelta
+		}
+		text, err := gen.Generate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return []string{text}, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := job.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	results := make([]string, len(prompts))
+	errs := make([]error, len(prompts))
+
+	var mu sync.Mutex
+	done := 0
+	var wg sync.WaitGroup
+	for i, p := range prompts {
+		wg.Add(1)
+		go func(i int, p promptPair) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			text, err := gen.Generate(ctx, GenerateRequest{
+				Model:        job.Model,
+				SystemPrompt: p.system,
+				UserPrompt:   p.user,
+				Schema:       schema,
+				OnRetry:      job.OnRetry,
+			})
+			if err != nil {
+				errs[i] = err
+				// One failed chunk fails the paper; stop spending on the others.
+				cancel()
+				return
+			}
+			results[i] = text
+
+			mu.Lock()
+			done++
+			if job.OnProgress != nil {
+				job.OnProgress(done, len(prompts))
+			}
+			mu.Unlock()
+		}(i, p)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil && err != context.Canceled {
+			return nil, fmt.Errorf("%d번째 묶음 생성 실패: %w", i+1, err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+var questionNumberRe = regexp.MustCompile(`^(\s*)(\d+)(\s*[.)])`)
+
+// mergeChunkOutputs renumbers the questions of every chunk so numbering continues
+// across chunks and collects all answer keys into one [정답] section.
+func mergeChunkOutputs(outputs []string) string {
+	var blocks, keyLines []string
+	next := 1
+	for _, out := range outputs {
+		body, key := splitAnswerKey(out)
+		answers := answerKeyEntries(key)
+		for _, block := range splitBlocks(body) {
+			local := 0
+			if m := questionNumberRe.FindStringSubmatch(block); m != nil {
+				local, _ = strconv.Atoi(m[2])
+				block = questionNumberRe.ReplaceAllString(block, fmt.Sprintf("${1}%d${3}", next))
+			}
+			blocks = append(blocks, block)
+			if ans, ok := answers[local]; ok {
+				keyLines = append(keyLines, fmt.Sprintf("%d. %s", next, ans))
+			} else {
+				keyLines = append(keyLines, fmt.Sprintf("%d. ?", next))
+			}
+			next++
+		}
+	}
+	return strings.Join(blocks, "\n---\n") + "\n\n[정답]\n" + strings.Join(keyLines, "\n") + "\n"
+}
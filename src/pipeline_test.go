@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// generatorFunc adapts a function to the Generator interface.
+type generatorFunc func(ctx context.Context, req GenerateRequest) (string, error)
+
+func (f generatorFunc) Generate(ctx context.Context, req GenerateRequest) (string, error) {
+	return f(ctx, req)
+}
+
+func TestMergeChunkOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []string
+		want    string
+	}{
+		{
+			name: "renumbers across chunks",
+			outputs: []string{
+				"1. a\n① x\n---\n2. b\n① y\n\n[정답]\n1. ②\n2. ⑤\n",
+				"1. c\n① z\n\n[정답]\n1. ③\n",
+			},
+			want: "1. a\n① x\n---\n2. b\n① y\n---\n3. c\n① z\n\n[정답]\n1. ②\n2. ⑤\n3. ③\n",
+		},
+		{
+			name: "keeps notes and written answers",
+			outputs: []string{
+				"1. a\n① x\n\n[정답]\n1. ④ (rise → fall)\n",
+				"1. b\n(a) _______\n\n[정답]\n1. (a) bank (b) run\n",
+			},
+			want: "1. a\n① x\n---\n2. b\n(a) _______\n\n[정답]\n1. ④ (rise → fall)\n2. (a) bank (b) run\n",
+		},
+		{
+			name:    "missing answer",
+			outputs: []string{"1. a\n① x\n---\n2. b\n① y\n\n[정답]\n2. 1\n"},
+			want:    "1. a\n① x\n---\n2. b\n① y\n\n[정답]\n1. ?\n2. ①\n",
+		},
+		{
+			name:    "chunk numbered from elsewhere",
+			outputs: []string{"1. a\n① x\n\n[정답]\n1. ①\n", "5. b\n① y\n\n[정답]\n5. ②\n"},
+			want:    "1. a\n① x\n---\n2. b\n① y\n\n[정답]\n1. ①\n2. ②\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeChunkOutputs(tt.outputs); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunGenerationBalancesAroundMalformedBlocks(t *testing.T) {
+	var blocks []string
+	for i, word := range []string{"bank", "run", "fair", "bear"} {
+		blocks = append(blocks, fmt.Sprintf("%d. 다음 빈칸에 들어갈 말로 가장 적절한 것은?\nThe ___ is here.\n① %s\n② x\n③ y\n④ z\n⑤ w", i+1, word))
+	}
+	blocks = append(blocks, "5. 질문\n① a\n② b\n③ c")
+	output := strings.Join(blocks, "\n---\n") + "\n\n[정답]\n1. ①\n2. ①\n3. ①\n4. ①\n5. ①\n"
+	gen := generatorFunc(func(ctx context.Context, req GenerateRequest) (string, error) { return output, nil })
+
+	var vocab []VocabPair
+	for _, w := range []string{"bank", "run", "fair", "bear"} {
+		vocab = append(vocab, VocabPair{Word: w, Meanings: []string{"뜻"}})
+	}
+	result, err := runGeneration(context.Background(), gen, GenerationJob{QuestionType: "빈칸 추론", Vocab: vocab, AnswerSeed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ParseErr == nil || !strings.Contains(result.ParseErr.Error(), "5번째 블록(문제 5)") {
+		t.Errorf("ParseErr = %v, want the malformed block", result.ParseErr)
+	}
+	if result.Seed != 7 {
+		t.Errorf("Seed = %d, want the answers balanced with seed 7", result.Seed)
+	}
+	if got := answerDistribution(result.Questions); got[0] == len(result.Questions) {
+		t.Errorf("answers were not balanced: %v", got)
+	}
+	if len(result.Questions) != 4 || strings.Contains(result.Text, "질문") {
+		t.Errorf("%d questions, text:\n%s", len(result.Questions), result.Text)
+	}
+}
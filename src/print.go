@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PrintConfig sets up the printable HTML and PDF export.
+type PrintConfig struct {
+	// Title is printed at the top of every paper (default "영어 어휘 평가").
+	Title string `json:"title,omitempty"`
+	// Fields are the blanks students fill in under the title (default 반, 번호, 이름, 날짜).
+	Fields []string `json:"fields,omitempty"`
+	// Font is the TrueType font embedded in PDF files. When empty, common
+	// Korean system fonts are tried.
+	Font string `json:"font,omitempty"`
+}
+
+func (c PrintConfig) title() string {
+	if c.Title == "" {
+		return "영어 어휘 평가"
+	}
+	return c.Title
+}
+
+func (c PrintConfig) fields() []string {
+	if len(c.Fields) == 0 {
+		return []string{"반", "번호", "이름", "날짜"}
+	}
+	return c.Fields
+}
+
+// printSection is one paper, or one form of a multi-form paper, read back from
+// its text so edits made in the output pane are printed too.
+type printSection struct {
+	Form      string // "A", "B", ... or "" for a single paper
+	Questions []printQuestion
+	Answers   []string   // lines of the [정답] section
+	Table     [][]string // the [문항 대조표], header row first
+}
+
+// printQuestion is a question block. Raw holds the lines of a block that could
+// not be read as a question, which is printed as it is.
+type printQuestion struct {
+	Question
+	Raw []string
+	// Matching holds the word and the meaning of every row of a word–meaning
+	// matching table, which is printed as two columns instead of the context.
+	Matching [][]string
+}
+
+// matchingRowRe reads a row of a matching table, e.g. "(1) bank   ⓐ 은행, 둑",
+// whose columns are lined up with spaces.
+var matchingRowRe = regexp.MustCompile(`^(\(\d+\)\s.*?)\s{2,}([ⓐ-ⓩ]\s.*)$`)
+
+// matchingRows returns the columns of context if every line is a row of a
+// matching table, or nil.
+func matchingRows(context []string) [][]string {
+	var rows [][]string
+	for _, line := range context {
+		m := matchingRowRe.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		rows = append(rows, []string{m[1], m[2]})
+	}
+	return rows
+}
+
+// printSections reads a paper or answer key as formatQuestions, formatForms
+// and splitPaper write it.
+func printSections(text string) []printSection {
+	locs := formHeaderRe.FindAllStringSubmatchIndex(text, -1)
+	if len(locs) == 0 {
+		return []printSection{readPrintSection("", text)}
+	}
+	var sections []printSection
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		sections = append(sections, readPrintSection(text[loc[2]:loc[3]], text[loc[1]:end]))
+	}
+	return sections
+}
+
+func readPrintSection(form, text string) printSection {
+	s := printSection{Form: form}
+	body, key := splitAnswerKey(text)
+	for _, block := range splitBlocks(body) {
+		q, err := parseQuestionBlock(block)
+		if err != nil {
+			s.Questions = append(s.Questions, printQuestion{Raw: strings.Split(block, "\n")})
+			continue
+		}
+		s.Questions = append(s.Questions, printQuestion{Question: q, Matching: matchingRows(q.Context)})
+	}
+
+	inTable := false
+	for _, line := range strings.Split(key, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case line == tableHeader:
+			inTable = true
+		case inTable:
+			s.Table = append(s.Table, strings.Fields(line))
+		default:
+			s.Answers = append(s.Answers, line)
+		}
+	}
+	if len(s.Table) > 0 {
+		// Rows have a number per form and the words, which may hold spaces.
+		forms := len(s.Table[0]) - 1
+		for i, row := range s.Table[1:] {
+			if len(row) > forms {
+				s.Table[i+1] = append(row[:forms:forms], strings.Join(row[forms:], " "))
+			}
+		}
+	}
+	return s
+}
+
+// isKey reports whether the section holds only answers, which are printed
+// without the fields for the student's name.
+func (s printSection) isKey() bool {
+	return len(s.Questions) == 0
+}
+
+// startsPage reports whether section i begins a new page with a header. The
+// keys of consecutive forms share pages.
+func startsPage(sections []printSection, i int) bool {
+	return !sections[i].isKey() || i == 0 || !sections[i-1].isKey()
+}
+
+// textSpan is a run of a line printed the same way.
+type textSpan struct {
+	Text      string
+	Underline bool
+}
+
+// markedSpans splits a line at its '①[...]' marks, underlining the marked part
+// and keeping the circled number in front of it.
+func markedSpans(line string) []textSpan {
+	var spans []textSpan
+	at := 0
+	for _, m := range underlineRe.FindAllStringSubmatchIndex(line, -1) {
+		if m[0] > at {
+			spans = append(spans, textSpan{Text: line[at:m[0]]})
+		}
+		spans = append(spans, textSpan{Text: line[m[2]:m[3]]}, textSpan{Text: line[m[4]:m[5]], Underline: true})
+		at = m[1]
+	}
+	if at < len(line) {
+		spans = append(spans, textSpan{Text: line[at:]})
+	}
+	return spans
+}
+
+// choicesPerRow returns how many choices are printed side by side in a column
+// of the given width: all five, three, or one per line. measure returns the
+// width of a choice in the unit of width, with room to the next one.
+func choicesPerRow(choices []string, width float64, measure func(string) float64) int {
+	widest := 0.0
+	for i, c := range choices {
+		widest = max(widest, measure(choiceMarks[i%len(choiceMarks)]+" "+c))
+	}
+	for _, n := range []int{5, 3} {
+		if widest*float64(n) <= width {
+			return n
+		}
+	}
+	return 1
+}
+
+// emWidth estimates the width of s in ems for the HTML layout, which leaves the
+// measuring to the browser: wide (Hangul, CJK) characters are 1em, others about half.
+func emWidth(s string) float64 {
+	w := 0.0
+	for _, r := range s {
+		if r >= 0x1100 {
+			w++
+		} else {
+			w += 0.55
+		}
+	}
+	return w
+}
+
+// htmlColumnEms is about the width of one column of the HTML layout in ems.
+const htmlColumnEms = 24
+
+// exportFormat returns "html", "pdf" or "text" by the extension of path.
+func exportFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return "html"
+	case ".pdf":
+		return "pdf"
+	}
+	return "text"
+}
+
+// renderDocument renders a paper or answer key for the file at path: a
+// printable HTML page or PDF by its extension, the text itself otherwise.
+func renderDocument(path, text string, cfg PrintConfig) ([]byte, error) {
+	switch exportFormat(path) {
+	case "html":
+		return renderHTML(text, cfg)
+	case "pdf":
+		return renderPDF(text, cfg)
+	}
+	return []byte(text), nil
+}
+
+type htmlPage struct {
+	Title    string
+	Fields   []string
+	Sections []htmlSection
+}
+
+type htmlSection struct {
+	printSection
+	Key, NewPage bool
+}
+
+var htmlFuncs = template.FuncMap{
+	"mark":        func(i int) string { return choiceMarks[i%len(choiceMarks)] },
+	"tableHeader": func() string { return tableHeader },
+	"spans":       markedSpans,
+	"bank":        func(line string) bool { return wordBankRe.MatchString(line) },
+	"perRow": func(choices []string) int {
+		return choicesPerRow(choices, htmlColumnEms, func(s string) float64 { return emWidth(s) + 1 })
+	},
+}
+
+var htmlTemplate = template.Must(template.New("paper").Funcs(htmlFuncs).Parse(`<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+@page { size: A4; margin: 15mm 12mm; }
+body { font-family: "Malgun Gothic", "Apple SD Gothic Neo", "Noto Sans KR", "NanumGothic", sans-serif; font-size: 10.5pt; line-height: 1.5; color: #000; margin: 0; }
+section.page + section.page { break-before: page; }
+header { border-bottom: 2px solid #000; margin-bottom: 4mm; padding-bottom: 2mm; }
+h1 { font-size: 16pt; text-align: center; margin: 0 0 3mm; }
+.fields { display: flex; justify-content: flex-end; gap: 6mm; }
+.fields span::after { content: ""; display: inline-block; width: 18mm; border-bottom: 1px solid #000; margin-left: 1mm; }
+.columns { column-count: 2; column-gap: 8mm; column-rule: 1px solid #888; }
+.question { break-inside: avoid; margin: 0 0 5mm; }
+.prompt { padding-left: 1.6em; text-indent: -1.6em; margin: 0 0 1mm; }
+.number { font-weight: bold; }
+.context { margin: 0 0 1mm 1.6em; }
+.context p { margin: 0; }
+.context p.bank { border: 1px solid #000; padding: 1mm 2mm; margin: 1mm 0; }
+.choices { list-style: none; display: flex; flex-wrap: wrap; margin: 0 0 0 1.6em; padding: 0; }
+.choices li { box-sizing: border-box; width: 100%; padding-left: 1.3em; text-indent: -1.3em; }
+.choices.row5 li { width: 20%; }
+.choices.row3 li { width: 33.33%; }
+.raw p { margin: 0; }
+table.matching { column-span: none; margin: 0 0 1mm 1.6em; }
+table.matching td { border: none; padding: 0 4mm 0 0; text-align: left; vertical-align: top; }
+h2 { font-size: 12pt; margin: 0 0 2mm; }
+.answers { list-style: none; padding: 0; margin: 0 0 4mm; }
+.answers li { break-inside: avoid; }
+table { border-collapse: collapse; column-span: all; margin-top: 4mm; }
+th, td { border: 1px solid #000; padding: 0.5mm 2mm; text-align: center; }
+td:last-child { text-align: left; }
+@media screen { body { max-width: 210mm; margin: 10mm auto; } }
+</style>
+</head>
+<body>
+{{- range .Sections}}
+<section{{if .NewPage}} class="page"{{end}}>
+{{- if .NewPage}}
+<header>
+<h1>{{$.Title}}{{if .Key}} 정답{{else if .Form}} ({{.Form}}형){{end}}</h1>
+{{- if not .Key}}
+<div class="fields">{{range $.Fields}}<span>{{.}}</span>{{end}}</div>
+{{- end}}
+</header>
+{{- end}}
+<div class="columns">
+{{- if and .Key .Form}}
+<h2>{{.Form}}형</h2>
+{{- end}}
+{{- range .Questions}}
+{{- if .Raw}}
+<div class="question raw">{{range .Raw}}<p>{{.}}</p>{{end}}</div>
+{{- else}}
+<div class="question">
+<p class="prompt"><span class="number">{{.Number}}.</span> {{.Prompt}}</p>
+{{- if .Matching}}
+<table class="matching">{{range .Matching}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>{{end}}</table>
+{{- else if .Context}}
+<div class="context">{{range .Context}}<p{{if bank .}} class="bank"{{end}}>{{range spans .}}{{if .Underline}}<u>{{.Text}}</u>{{else}}{{.Text}}{{end}}{{end}}</p>{{end}}</div>
+{{- end}}
+{{- if and .Choices (not .Underlined)}}
+<ol class="choices row{{perRow .Choices}}">{{range $i, $c := .Choices}}<li>{{mark $i}} {{$c}}</li>{{end}}</ol>
+{{- end}}
+</div>
+{{- end}}
+{{- end}}
+{{- if and .Answers (not .Key)}}
+<h2>[정답]</h2>
+{{- end}}
+{{- if .Answers}}
+<ul class="answers">{{range .Answers}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+{{- if .Table}}
+<h2>{{tableHeader}}</h2>
+<table>
+<tr>{{range index .Table 0}}<th>{{.}}</th>{{end}}</tr>
+{{- range slice .Table 1}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+</div>
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+// renderHTML renders the text as an A4 exam page: two columns, a header with
+// the title and name fields, and a page break before every form. Questions
+// are not split across columns or pages.
+func renderHTML(text string, cfg PrintConfig) ([]byte, error) {
+	page := htmlPage{Title: cfg.title(), Fields: cfg.fields()}
+	sections := printSections(text)
+	for i, s := range sections {
+		page.Sections = append(page.Sections, htmlSection{printSection: s, Key: s.isKey(), NewPage: startsPage(sections, i)})
+	}
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, page); err != nil {
+		return nil, fmt.Errorf("HTML 만들기 실패: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -5,106 +5,130 @@ import (
 	"strings"
 )
 
-func buildPrompts(parsed []VocabPair, questionType string, numSentences int) (systemPrompt, userPrompt string) {
-	distributionRule := "2. CRITICAL: The position of the correct answer MUST be truly and unpredictably randomized to ensure a balanced distribution. For the entire set of questions, each choice position (①, ②, ③, ④, ⑤) should be the correct answer approximately 20% of the time. DO NOT use any discernible pattern (e.g., 1, 2, 3, 4, 5 or 5, 4, 3, 2, 1). The sequence of correct answers must appear random and chaotic."
+// buildPrompts builds the system and user prompts for one request from the
+// question type's template. Broken templates are rejected when they are loaded,
+// but a block can still fail on data the load check did not try. With
+// structured set, the model is told to answer with JSON matching questionSchema
+// instead of the ①–⑤ text layout with a trailing [정답] section. Answer positions are not the model's concern;
+// balanceAnswers reshuffles the choices afterwards. The learner profile sets the
+// level rules of the style block.
+func buildPrompts(parsed []VocabPair, questionType string, numSentences int, learner LearnerProfile, structured bool) (systemPrompt, userPrompt string, err error) {
 	selfCorrectionRule := "### Final Review\nBefore concluding your response, you MUST review the entire generated text one last time to ensure every single rule has been followed. Pay special attention that every question has exactly 5 numbered choices (① to ⑤). If you find any mistake, you must correct it before finishing."
 
-	var systemPromptLines []string
-	switch questionType {
-	case "빈칸 추론":
-		systemPromptLines = []string{
-			"You are an expert English vocabulary test maker for Korean students.",
-			"Your task is to create multiple-choice questions that test understanding of words in context.",
-			"Strictly follow all rules below.",
-			"",
-			"### Main Rule",
-			"For each WORD and for each of its SENSEs, you must generate a complete question block.",
-			"",
-			"### Word Selection & Question Style Rule",
-			"1. PRIORITY: Focus on polysemous words—those with multiple, distinct meanings (e.g., different parts of speech like 'conduct' as a noun vs. verb, or different senses like 'bank' of a river vs. a financial institution).",
-			"2. GOAL: The questions should be intentionally challenging, designed to confuse the test-taker and test their ability to discern the correct meaning from context.",
-			"",
-			"### Answer Generation Rules",
-			"1. CRITICAL: DO NOT mark the correct answer in the choices. Instead, create a separate `[정답]` section at the very end of the entire output, listing each question number and its correct choice number.",
-			distributionRule,
-			"",
-			"### Output Structure (per question)",
-			"1. Start with the question number (e.g., '1.').",
-			"2. Add the title: '다음 빈칸에 공통으로 들어갈 말로 가장 적절한 것은?'",
-			fmt.Sprintf("3. Provide exactly %d distinct English sentences as context. Each sentence must have the word blanked out as '_______'.", numSentences),
-			"4. Provide exactly 5 answer choices (①, ②, ③, ④, ⑤).",
-			"5. The choices must include one correct answer (the original WORD) and four plausible but incorrect distractors.",
-			"6. Separate each full question block with a '---' line.",
-			"",
-			selfCorrectionRule,
+	qt, _ := findQuestionType(questionType)
+	data := promptData{
+		Words:       formatVocabList(parsed),
+		Sentences:   numSentences,
+		Annotated:   hasAnnotations(parsed),
+		PassageSize: qt.Passage,
+	}
+	if desc, rules, ok := learner.describe(); ok {
+		data.Difficulty, data.Learner, data.DifficultyRules = learner.Difficulty, desc, rules
+	}
+	blocks := make(map[string]string)
+	for _, block := range []string{"role", "task", "main_rule", "style", "user"} {
+		if blocks[block], err = qt.render(block, data); err != nil {
+			return "", "", fmt.Errorf("'%s' 프롬프트 만들기 실패: %w", questionType, err)
 		}
-	case "영영풀이":
-		systemPromptLines = []string{
-			"You are an expert English vocabulary test maker for Korean students.",
-			"Your task is to create multiple-choice questions based on English definitions.",
-			"Strictly follow all rules below.",
-			"",
-			"### Main Rule",
-			"For each WORD, you must generate one complete multiple-choice question.",
-			"",
-			"### Word Selection & Question Style Rule",
-			"1. PRIORITY: Focus on polysemous words—those with multiple, distinct meanings (e.g., different parts of speech like 'conduct' as a noun vs. verb, or different senses like 'bank' of a river vs. a financial institution).",
-			"2. GOAL: The questions should be intentionally challenging, designed to confuse the test-taker and test their ability to discern the correct meaning from context.",
-			"",
-			"### Answer Generation Rules",
-			"1. CRITICAL: DO NOT mark the correct answer in the choices. Instead, create a separate `[정답]` section at the very end of the entire output, listing each question number and its correct choice number.",
-			distributionRule,
-			"",
-			"### Output Structure (per question)",
-			"1. Start with the question number (e.g., '1.').",
-			"2. Add the title: '다음 영어 설명에 해당하는 단어는?'",
-			"3. Provide the English definition of the WORD as the question body.",
-			"4. Provide exactly 5 answer choices (①, ②, ③, ④, ⑤): one correct answer (the original WORD) and four plausible distractors (e.g., synonyms, related words).",
-			"5. Separate each full question block with a '---' line.",
-			"",
-			selfCorrectionRule,
+	}
+	structure, err := qt.renderLines("structure", data)
+	if err != nil {
+		return "", "", fmt.Errorf("'%s' 프롬프트 만들기 실패: %w", questionType, err)
+	}
+	role, task, mainRule, style := blocks["role"], blocks["task"], blocks["main_rule"], blocks["style"]
+
+	systemPromptLines := []string{
+		role,
+		task,
+		"Strictly follow all rules below.",
+		"",
+		"### Main Rule",
+		mainRule,
+		"",
+		style,
+		"",
+	}
+
+	if structured {
+		systemPromptLines = append(systemPromptLines, "### Question Content (per question)")
+		for i, rule := range structure {
+			systemPromptLines = append(systemPromptLines, fmt.Sprintf("%d. %s", i+1, rule))
 		}
-	case "뜻풀이 판단":
-		systemPromptLines = []string{
-			"You are an expert English vocabulary test maker for Korean students.",
-			"Your task is to create multiple-choice questions that test the precise definition of a word.",
-			"Strictly follow all rules below.",
-			"",
-			"### Main Rule",
-			"For each WORD, you must generate one complete multiple-choice question asking for its correct definition.",
-			"",
-			"### Word Selection & Question Style Rule",
-			"1. PRIORITY: Focus on polysemous words—those with multiple, distinct meanings (e.g., different parts of speech like 'conduct' as a noun vs. verb, or different senses like 'bank' of a river vs. a financial institution).",
-			"2. GOAL: The questions should be intentionally challenging, designed to confuse the test-taker and test their ability to discern the correct meaning from context.",
+		systemPromptLines = append(systemPromptLines,
 			"",
+			"### Output Format",
+			"Respond ONLY with a JSON object of the form {\"questions\": [...]}, one entry per question, matching the provided JSON schema.",
+			"- `prompt`: the title.",
+			"- `context`: the question body (context sentences or definition), one string per sentence. Use an empty array if there is no body.",
+			"- `choices`: exactly 5 strings in display order, WITHOUT the ①–⑤ marks.",
+			"- `answer_index`: the 0-based index of the correct choice in `choices`.",
+			"- `word`, `pos` and `sense`: the WORD, its part of speech as written in the vocabulary list (an empty string if it has none) and the Korean SENSE that the question tests.",
+		)
+	} else {
+		systemPromptLines = append(systemPromptLines,
 			"### Answer Generation Rules",
 			"1. CRITICAL: DO NOT mark the correct answer in the choices. Instead, create a separate `[정답]` section at the very end of the entire output, listing each question number and its correct choice number.",
-			distributionRule,
+		)
+		if qt.Passage == 0 && sharesWords(parsed) {
+			systemPromptLines = append(systemPromptLines,
+				"2. Some WORDs are listed more than once with different parts of speech. For a question on such a WORD, write the part of speech it tests in parentheses after the answer, exactly as written in the list, e.g. '3. ② (v.)'.",
+			)
+		}
+		systemPromptLines = append(systemPromptLines,
 			"",
 			"### Output Structure (per question)",
 			"1. Start with the question number (e.g., '1.').",
-			"2. Add the title: '다음 단어 <WORD>의 영영풀이로 가장 적절한 것은?' (replace <WORD> with the actual word).",
-			"3. Provide exactly 5 definition choices (①, ②, ③, ④, ⑤): one perfectly correct definition and four subtly incorrect but plausible definitions.",
-			"4. Separate each full question block with a '---' line.",
+		)
+		for i, rule := range structure {
+			systemPromptLines = append(systemPromptLines, fmt.Sprintf("%d. %s", i+2, rule))
+		}
+		systemPromptLines = append(systemPromptLines,
+			fmt.Sprintf("%d. Separate each full question block with a '---' line.", len(structure)+2),
 			"",
 			selfCorrectionRule,
-		}
+		)
 	}
 	systemPrompt = strings.Join(systemPromptLines, "\n")
 
-	var parsedForModel []string
+	return systemPrompt, blocks["user"], nil
+}
+
+// formatVocabList renders the list the way the teacher would type it, with a
+// "[Unit 3]" heading wherever the unit changes.
+func formatVocabList(parsed []VocabPair) string {
+	var lines []string
+	unit := ""
 	for _, p := range parsed {
-		parsedForModel = append(parsedForModel, fmt.Sprintf("%s = %s", p.Word, strings.Join(p.Meanings, ", ")))
+		if p.Unit != unit && p.Unit != "" {
+			lines = append(lines, "["+p.Unit+"]")
+		}
+		unit = p.Unit
+		lines = append(lines, formatVocabLine(p))
 	}
-	parsedForModelText := strings.Join(parsedForModel, "\n")
+	return strings.Join(lines, "\n")
+}
 
-	userPromptLines := []string{
-		"Here is the list of vocabulary. Create test questions based on these words, strictly following all rules defined in the system instructions.",
-		"",
-		"[Vocabulary List]",
-		parsedForModelText,
+// sharesWords reports whether some word is listed with more than one part of
+// speech, so the answers must tell which entry they test.
+func sharesWords(parsed []VocabPair) bool {
+	seen := make(map[string]bool)
+	for _, p := range parsed {
+		word := strings.ToLower(p.Word)
+		if seen[word] {
+			return true
+		}
+		seen[word] = true
 	}
-	userPrompt = strings.Join(userPromptLines, "\n")
+	return false
+}
 
-	return 
+// hasAnnotations reports whether any entry uses the optional parts of the input
+// syntax, so plain lists keep the shorter prompt.
+func hasAnnotations(parsed []VocabPair) bool {
+	for _, p := range parsed {
+		if p.POS != "" || p.Examples != nil || p.Unit != "" || len(p.Tags) > 0 || p.Note != "" {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatVocabList(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      string
+		annotated bool
+	}{
+		{"plain", "bank = 은행\nrun = 달리다", "bank = 은행\nrun = 달리다", false},
+		{
+			name:      "units",
+			input:     "[Unit 1]\nbank = 은행\nrun = 달리다\n[Unit 2]\nconduct (n.) = 행동",
+			want:      "[Unit 1]\nbank = 은행\nrun = 달리다\n[Unit 2]\nconduct (n.) = 행동",
+			annotated: true,
+		},
+		{"words before the first unit", "bank = 은행\n[Unit 1]\nrun = 달리다", "bank = 은행\n[Unit 1]\nrun = 달리다", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, _ := parseVocabLines(tt.input)
+			if got := formatVocabList(parsed); got != tt.want {
+				t.Errorf("formatVocabList = %q, want %q", got, tt.want)
+			}
+			if got := hasAnnotations(parsed); got != tt.annotated {
+				t.Errorf("hasAnnotations = %v, want %v", got, tt.annotated)
+			}
+		})
+	}
+}
+
+func TestBuildPromptsListsUnits(t *testing.T) {
+	parsed, _ := parseVocabLines("[Unit 3]\nbank = 은행")
+	system, user, err := buildPrompts(parsed, "영영풀이", 0, LearnerProfile{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(user, "[Unit 3]\nbank = 은행") {
+		t.Errorf("the unit is not in the vocabulary list:\n%s", user)
+	}
+	if !strings.Contains(system, "'[Unit 3]'") {
+		t.Errorf("the system prompt does not explain unit headings:\n%s", system)
+	}
+}
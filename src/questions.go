@@ -0,0 +1,486 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const numChoices = 5
+
+// Question is one item of a generated paper: multiple choice, or written when
+// it has no choices and an AnswerText.
+type Question struct {
+	Number int
+	// Prompt is the direction line, e.g. '다음 빈칸에 공통으로 들어갈 말로 가장 적절한 것은?'.
+	Prompt string
+	// Context holds the body lines between the prompt and the choices
+	// (context sentences, an English definition, ...).
+	Context []string
+	Choices []string
+	// Answer is the index into Choices of the correct choice, or -1 if unknown.
+	Answer int
+	// AnswerText is the answer of a written question (spelling, matching table).
+	AnswerText string
+	// Word, POS and Sense identify the vocabulary entry the question tests.
+	Word  string
+	POS   string
+	Sense string
+	// Words lists the entries of a question that tests several at once, such as
+	// a matching table, as headwords like "conduct (n.)"; Word is empty then.
+	Words []string
+	// Underlined means the choices are the parts of the passage marked
+	// '①[...]' to '⑤[...]', so they are not listed again and keep their order.
+	Underlined bool
+	// Explanation follows the answer in the [정답] section, e.g. the correction
+	// of an error-detection question.
+	Explanation string
+	// Type is the question type that made the question; test forms keep the
+	// questions of one type together.
+	Type string
+}
+
+// key is the key of the vocabulary entry the question tests.
+func (q Question) key() string {
+	return entryKey(q.Word, q.POS)
+}
+
+// AnswerMark returns the circled number of the correct choice, the written
+// answer, or "?" if unknown.
+func (q Question) AnswerMark() string {
+	if q.AnswerText != "" {
+		return q.AnswerText
+	}
+	if q.Answer < 0 || q.Answer >= len(choiceMarks) {
+		return "?"
+	}
+	return choiceMarks[q.Answer]
+}
+
+// QuestionParseError describes one block of model output that could not be read as a question.
+type QuestionParseError struct {
+	Block  int
+	Number int
+	Reason string
+}
+
+func (e *QuestionParseError) Error() string {
+	if e.Number > 0 {
+		return fmt.Sprintf("%d번째 블록(문제 %d): %s", e.Block, e.Number, e.Reason)
+	}
+	return fmt.Sprintf("%d번째 블록: %s", e.Block, e.Reason)
+}
+
+var (
+	answerKeyEntryRe = regexp.MustCompile(`(\d+)\s*[.:)\-]?\s*([①②③④⑤]|[1-5](?:\D|$))`)
+	answerHeaderRe   = regexp.MustCompile(`(?m)^\s*[#*\s]*\[?\s*정답\s*\]?[*\s]*:?\s*$`)
+	blockSeparatorRe = regexp.MustCompile(`(?m)^\s*-{3,}\s*$`)
+	questionHeadRe   = regexp.MustCompile(`^\s*\**\s*(\d+)\s*[.)]\**\s*(.*)$`)
+	choiceSplitRe    = regexp.MustCompile(`[①②③④⑤]`)
+	// writtenAnswerRe reads a passage answer line such as "3. (a) bank (b) run".
+	writtenAnswerRe = regexp.MustCompile(`(?m)^\s*\**\s*(\d+)\s*[.:)]\**\s*(\(a\).*?)\s*$`)
+	// explainedAnswerRe reads an answer line with a note, such as "4. ③ (increase → decrease)".
+	explainedAnswerRe = regexp.MustCompile(`(?m)^\s*\**\s*(\d+)\s*[.:)]\**\s*[①②③④⑤1-5]\s*\((.+)\)\s*$`)
+	// underlineRe finds a marked part of a passage, e.g. "③[conducted]".
+	underlineRe = regexp.MustCompile(`([①②③④⑤])\s*\[([^\[\]]+)\]`)
+)
+
+// splitAnswerKey separates the question body from the trailing [정답] section.
+func splitAnswerKey(text string) (body, key string) {
+	locs := answerHeaderRe.FindAllStringIndex(text, -1)
+	if len(locs) == 0 {
+		return text, ""
+	}
+	last := locs[len(locs)-1]
+	return text[:last[0]], text[last[1]:]
+}
+
+// splitBlocks splits a question body on its '---' separator lines, dropping empty blocks.
+func splitBlocks(body string) []string {
+	var blocks []string
+	for _, b := range blockSeparatorRe.Split(body, -1) {
+		if b = strings.TrimSpace(b); b != "" {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+// parseWrittenAnswers reads the answers of passage questions, which list every
+// blank instead of a choice mark, into question number -> answer text.
+func parseWrittenAnswers(key string) map[int]string {
+	answers := make(map[int]string)
+	for _, m := range writtenAnswerRe.FindAllStringSubmatch(key, -1) {
+		n, _ := strconv.Atoi(m[1])
+		answers[n] = m[2]
+	}
+	return answers
+}
+
+// parseAnswerNotes reads the notes some answer lines carry after the mark into
+// question number -> note.
+func parseAnswerNotes(key string) map[int]string {
+	notes := make(map[int]string)
+	for _, m := range explainedAnswerRe.FindAllStringSubmatch(key, -1) {
+		n, _ := strconv.Atoi(m[1])
+		notes[n] = strings.TrimSpace(m[2])
+	}
+	return notes
+}
+
+// answerKeyEntries reads every answer of a [정답] section as it would be
+// written back by formatAnswerKey: the mark with its note, or the written answer.
+func answerKeyEntries(key string) map[int]string {
+	entries := parseAnswerKey(key)
+	for n, note := range parseAnswerNotes(key) {
+		if mark, ok := entries[n]; ok {
+			entries[n] = fmt.Sprintf("%s (%s)", mark, note)
+		}
+	}
+	for n, text := range parseWrittenAnswers(key) {
+		entries[n] = text
+	}
+	return entries
+}
+
+// parseAnswerKey reads entries like "1. ③", "2-①" or "3: 4" into question number -> choice mark.
+func parseAnswerKey(key string) map[int]string {
+	answers := make(map[int]string)
+	for _, m := range answerKeyEntryRe.FindAllStringSubmatch(key, -1) {
+		n, _ := strconv.Atoi(m[1])
+		answers[n] = normalizeChoiceMark(strings.TrimSpace(m[2]))
+	}
+	return answers
+}
+
+var choiceMarks = []string{"①", "②", "③", "④", "⑤"}
+
+// normalizeChoiceMark turns a plain digit answer into its circled form.
+func normalizeChoiceMark(s string) string {
+	if len(s) > 0 && s[0] >= '1' && s[0] <= '5' {
+		return choiceMarks[s[0]-'1']
+	}
+	return s
+}
+
+// parseQuestions turns model output made of '---'-separated blocks and a trailing
+// [정답] section into questions. Well-formed questions are always returned; the
+// error joins a QuestionParseError for every malformed block.
+func parseQuestions(text string) ([]Question, error) {
+	body, key := splitAnswerKey(text)
+	answers := parseAnswerKey(key)
+	written := parseWrittenAnswers(key)
+	notes := parseAnswerNotes(key)
+
+	var questions []Question
+	var errs []error
+	for i, block := range splitBlocks(body) {
+		q, err := parseQuestionBlock(block)
+		if err != nil {
+			err.Block = i + 1
+			errs = append(errs, err)
+			continue
+		}
+		if len(q.Choices) == 0 {
+			// A question without choices is answered in writing, blank by blank.
+			text, ok := written[q.Number]
+			if !ok {
+				errs = append(errs, &QuestionParseError{Block: i + 1, Number: q.Number, Reason: "선택지가 0개입니다 (5개 필요)"})
+				continue
+			}
+			q.AnswerText = text
+			questions = append(questions, q)
+			continue
+		}
+		if mark, ok := answers[q.Number]; ok {
+			q.Answer = choiceIndex(mark)
+		}
+		q.Explanation = notes[q.Number]
+		if q.Answer < 0 {
+			errs = append(errs, &QuestionParseError{Block: i + 1, Number: q.Number, Reason: "[정답]에 이 문제의 답이 없습니다"})
+		}
+		questions = append(questions, q)
+	}
+	if len(questions) == 0 && len(errs) == 0 {
+		errs = append(errs, errors.New("문제 블록을 찾을 수 없습니다"))
+	}
+	return questions, errors.Join(errs...)
+}
+
+func parseQuestionBlock(block string) (Question, *QuestionParseError) {
+	q := Question{Answer: -1}
+	lines := strings.Split(block, "\n")
+
+	head := questionHeadRe.FindStringSubmatch(lines[0])
+	if head == nil {
+		return q, &QuestionParseError{Reason: fmt.Sprintf("문제 번호로 시작하지 않습니다: %q", strings.TrimSpace(lines[0]))}
+	}
+	q.Number, _ = strconv.Atoi(head[1])
+	q.Prompt = strings.TrimSpace(head[2])
+
+	for _, raw := range lines[1:] {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		if loc := choiceSplitRe.FindStringIndex(line); loc != nil && loc[0] == 0 && !startsUnderlined(line) {
+			// A line may hold several choices, e.g. "① a  ② b".
+			for _, c := range choiceSplitRe.Split(line, -1)[1:] {
+				q.Choices = append(q.Choices, strings.TrimSpace(c))
+			}
+			continue
+		}
+		if len(q.Choices) > 0 {
+			return q, &QuestionParseError{Number: q.Number, Reason: fmt.Sprintf("선택지 뒤에 알 수 없는 줄이 있습니다: %q", line)}
+		}
+		if q.Prompt == "" {
+			q.Prompt = line
+			continue
+		}
+		q.Context = append(q.Context, line)
+	}
+
+	if q.Prompt == "" {
+		return q, &QuestionParseError{Number: q.Number, Reason: "문제 지시문이 없습니다"}
+	}
+	if len(q.Choices) == 0 {
+		// An error-detection passage marks its choices in the text instead.
+		var marked []string
+		for _, line := range q.Context {
+			for _, m := range underlineRe.FindAllStringSubmatch(line, -1) {
+				if len(marked) < numChoices && m[1] != choiceMarks[len(marked)] {
+					return q, &QuestionParseError{Number: q.Number, Reason: fmt.Sprintf("밑줄 친 부분 %s의 순서가 맞지 않습니다", m[1])}
+				}
+				marked = append(marked, strings.TrimSpace(m[2]))
+			}
+		}
+		switch len(marked) {
+		case 0:
+		case numChoices:
+			q.Choices, q.Underlined = marked, true
+		default:
+			return q, &QuestionParseError{Number: q.Number, Reason: fmt.Sprintf("밑줄 친 부분이 %d개입니다 (5개 필요)", len(marked))}
+		}
+	}
+	if len(q.Choices) != numChoices && len(q.Choices) != 0 {
+		return q, &QuestionParseError{Number: q.Number, Reason: fmt.Sprintf("선택지가 %d개입니다 (5개 필요)", len(q.Choices))}
+	}
+	return q, nil
+}
+
+// startsUnderlined reports a passage line that begins with a marked part, which
+// is not a line of choices.
+func startsUnderlined(line string) bool {
+	loc := underlineRe.FindStringIndex(line)
+	return loc != nil && loc[0] == 0
+}
+
+// choiceIndex maps a circled mark to its 0-based index, or -1.
+func choiceIndex(mark string) int {
+	for i, m := range choiceMarks {
+		if m == mark {
+			return i
+		}
+	}
+	return -1
+}
+
+// attachSources fills Word and POS by matching each question against the
+// vocabulary list: first the correct choice, then any word mentioned in the
+// prompt or context. Sense is only filled when the matched entry has a single
+// meaning.
+func attachSources(questions []Question, vocab []VocabPair) {
+	count := make(map[string]int) // questions per entry so far
+	for i := range questions {
+		q := &questions[i]
+		if len(q.Words) == 0 && q.AnswerText != "" {
+			for _, a := range blankAnswers(q.AnswerText) {
+				// A word listed with several parts of speech fills its blanks in list order.
+				for _, v := range entriesOf(vocab, a) {
+					if indexOfChoice(q.Words, headword(v)) < 0 {
+						q.Words = append(q.Words, headword(v))
+						break
+					}
+				}
+			}
+		}
+		if len(q.Words) == 0 && q.Underlined {
+			// The wrong part may be an inflected or replaced WORD; the correction
+			// in the explanation names it.
+			text := strings.ToLower(strings.Join(q.Choices, " ") + " " + q.Explanation)
+			for _, v := range vocab {
+				if containsWordForm(text, v.Word) && indexOfChoice(q.Words, headword(v)) < 0 {
+					q.Words = append(q.Words, headword(v))
+				}
+			}
+		}
+		if len(q.Words) > 0 {
+			continue
+		}
+		if q.Word == "" && q.Answer >= 0 {
+			if entries := entriesOf(vocab, q.Choices[q.Answer]); len(entries) > 0 {
+				q.Word = entries[0].Word
+			}
+		}
+		if q.Word == "" {
+			text := strings.ToLower(q.Prompt + " " + strings.Join(q.Context, " "))
+			for _, cand := range vocab {
+				if containsWord(text, strings.ToLower(cand.Word)) {
+					q.Word = cand.Word
+					break
+				}
+			}
+		}
+		entries := entriesOf(vocab, q.Word)
+		if len(entries) == 0 {
+			continue
+		}
+		v := pickEntry(q, entries, count)
+		q.Word, q.POS = v.Word, v.POS
+		count[v.key()]++
+		if q.Sense == "" && len(v.Meanings) == 1 {
+			q.Sense = v.Meanings[0]
+		}
+	}
+}
+
+// pickEntry chooses which entry of the same word a question tests: the one with
+// its part of speech, given by structured output or noted after the answer as
+// in "3. ② (v.)", then the one that has its sense, and otherwise the one with
+// the fewest questions so far. A part-of-speech note is removed from the
+// explanation.
+func pickEntry(q *Question, entries []VocabPair, count map[string]int) VocabPair {
+	for _, v := range entries {
+		if v.POS != "" && q.Explanation != "" && normalizePOS(q.Explanation) == normalizePOS(v.POS) {
+			q.Explanation = ""
+			return v
+		}
+	}
+	if len(entries) == 1 {
+		return entries[0]
+	}
+	for _, v := range entries {
+		if q.POS != "" && normalizePOS(q.POS) == normalizePOS(v.POS) {
+			return v
+		}
+	}
+	for _, v := range entries {
+		if q.Sense != "" && indexOfChoice(v.Meanings, q.Sense) >= 0 {
+			return v
+		}
+	}
+	best := entries[0]
+	for _, v := range entries[1:] {
+		if count[v.key()] < count[best.key()] {
+			best = v
+		}
+	}
+	return best
+}
+
+// entriesOf returns the entries of word, one per part of speech it is listed with.
+func entriesOf(vocab []VocabPair, word string) []VocabPair {
+	var entries []VocabPair
+	for _, v := range vocab {
+		if strings.EqualFold(strings.TrimSpace(word), v.Word) {
+			entries = append(entries, v)
+		}
+	}
+	return entries
+}
+
+// findVocab returns the entry of word with the given part of speech.
+func findVocab(vocab []VocabPair, word, pos string) (VocabPair, bool) {
+	key := entryKey(word, pos)
+	for _, v := range vocab {
+		if v.key() == key {
+			return v, true
+		}
+	}
+	return VocabPair{}, false
+}
+
+// containsWord reports whether word occurs in text on ASCII letter boundaries.
+func containsWord(text, word string) bool {
+	if word == "" {
+		return false
+	}
+	start, _ := indexWord(text, word)
+	return start >= 0
+}
+
+// containsWordForm is containsWord that also accepts the regular inflections
+// of word, e.g. "conducted" or "studies".
+func containsWordForm(text, word string) bool {
+	for _, form := range wordForms(strings.ToLower(word)) {
+		if containsWord(text, form) {
+			return true
+		}
+	}
+	return false
+}
+
+// wordForms returns word with its regular -s, -ed and -ing forms. Phrases
+// inflect their first word.
+func wordForms(word string) []string {
+	head, rest, _ := strings.Cut(word, " ")
+	if rest != "" {
+		rest = " " + rest
+	}
+	forms := []string{head, head + "s", head + "es", head + "ed", head + "d", head + "ing"}
+	if n := len(head); n > 1 {
+		last := head[n-1]
+		switch {
+		case last == 'e':
+			forms = append(forms, head[:n-1]+"ing")
+		case last == 'y':
+			forms = append(forms, head[:n-1]+"ies", head[:n-1]+"ied")
+		case isASCIILetter(last) && !strings.ContainsRune("aeiouwxy", rune(last)):
+			forms = append(forms, head+head[n-1:]+"ed", head+head[n-1:]+"ing")
+		}
+	}
+	for i := range forms {
+		forms[i] += rest
+	}
+	return forms
+}
+
+func isASCIILetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// formatQuestions renders questions back into the plain-text paper layout,
+// ending with a [정답] section.
+func formatQuestions(questions []Question) string {
+	var blocks []string
+	for _, q := range questions {
+		blocks = append(blocks, formatQuestion(q))
+	}
+	return strings.Join(blocks, "\n---\n") + "\n\n" + formatAnswerKey(questions)
+}
+
+func formatQuestion(q Question) string {
+	lines := []string{fmt.Sprintf("%d. %s", q.Number, q.Prompt)}
+	lines = append(lines, q.Context...)
+	if q.Underlined {
+		return strings.Join(lines, "\n")
+	}
+	for i, c := range q.Choices {
+		lines = append(lines, fmt.Sprintf("%s %s", choiceMarks[i], c))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatAnswerKey(questions []Question) string {
+	lines := []string{"[정답]"}
+	for _, q := range questions {
+		line := fmt.Sprintf("%d. %s", q.Number, q.AnswerMark())
+		if q.Explanation != "" {
+			line += fmt.Sprintf(" (%s)", q.Explanation)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
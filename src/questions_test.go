@@ -0,0 +1,179 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQuestions(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []Question
+		wantErr string // substring of the error, "" for none
+	}{
+		{
+			name: "choices and key",
+			text: "1. 다음 빈칸에 들어갈 말로 가장 적절한 것은?\nHe sat on the ___ of the river.\n① bank\n② run\n③ fair\n④ bear\n⑤ light\n---\n2. 다음 영영풀이에 해당하는 단어는?\nthe way a person behaves\n① bank  ② conduct  ③ fair  ④ bear  ⑤ light\n\n[정답]\n1. ①\n2. ②\n",
+			want: []Question{
+				{Number: 1, Prompt: "다음 빈칸에 들어갈 말로 가장 적절한 것은?", Context: []string{"He sat on the ___ of the river."}, Choices: []string{"bank", "run", "fair", "bear", "light"}, Answer: 0},
+				{Number: 2, Prompt: "다음 영영풀이에 해당하는 단어는?", Context: []string{"the way a person behaves"}, Choices: []string{"bank", "conduct", "fair", "bear", "light"}, Answer: 1},
+			},
+		},
+		{
+			name: "digit answers and notes",
+			text: "**1.** 어법상 틀린 것은?\n① a\n② b\n③ c\n④ d\n⑤ e\n\n**[정답]**\n1: 4 (increase → decrease)\n",
+			want: []Question{
+				{Number: 1, Prompt: "어법상 틀린 것은?", Choices: []string{"a", "b", "c", "d", "e"}, Answer: 3, Explanation: "increase → decrease"},
+			},
+		},
+		{
+			name: "written passage answer",
+			text: "1. 빈칸에 알맞은 말을 [보기]에서 고르시오.\nThe (a) _______ was closed, so we (b) _______ home.\n[보기] bank / ran / fair\n\n[정답]\n1. (a) bank (b) ran\n",
+			want: []Question{
+				{Number: 1, Prompt: "빈칸에 알맞은 말을 [보기]에서 고르시오.", Context: []string{"The (a) _______ was closed, so we (b) _______ home.", "[보기] bank / ran / fair"}, Answer: -1, AnswerText: "(a) bank (b) ran"},
+			},
+		},
+		{
+			name: "underlined passage",
+			text: "1. 문맥상 낱말의 쓰임이 적절하지 않은 것은?\nPrices ①[rose] as demand ②[fell] and ③[supply] ④[stayed] ⑤[flat].\n\n[정답]\n1. ②\n",
+			want: []Question{
+				{Number: 1, Prompt: "문맥상 낱말의 쓰임이 적절하지 않은 것은?", Context: []string{"Prices ①[rose] as demand ②[fell] and ③[supply] ④[stayed] ⑤[flat]."}, Choices: []string{"rose", "fell", "supply", "stayed", "flat"}, Answer: 1, Underlined: true},
+			},
+		},
+		{
+			name: "missing answer is kept",
+			text: "1. 질문\n① a\n② b\n③ c\n④ d\n⑤ e\n",
+			want: []Question{
+				{Number: 1, Prompt: "질문", Choices: []string{"a", "b", "c", "d", "e"}, Answer: -1},
+			},
+			wantErr: "[정답]에 이 문제의 답이 없습니다",
+		},
+		{
+			name: "malformed block is dropped",
+			text: "1. 질문\n① a\n② b\n③ c\n---\n2. 질문\n① a\n② b\n③ c\n④ d\n⑤ e\n\n[정답]\n1. ①\n2. ⑤\n",
+			want: []Question{
+				{Number: 2, Prompt: "질문", Choices: []string{"a", "b", "c", "d", "e"}, Answer: 4},
+			},
+			wantErr: "1번째 블록(문제 1): 선택지가 3개입니다",
+		},
+		{
+			name:    "no question",
+			text:    "Sorry, I cannot help with that.",
+			wantErr: "문제 번호로 시작하지 않습니다",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseQuestions(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("questions = %#v, want %#v", got, tt.want)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatQuestionsRoundTrip(t *testing.T) {
+	questions := []Question{
+		{Number: 1, Prompt: "다음 빈칸에 들어갈 말로 가장 적절한 것은?", Context: []string{"He sat on the ___ of the river."}, Choices: []string{"bank", "run", "fair", "bear", "light"}, Answer: 2},
+		{Number: 2, Prompt: "어법상 틀린 것은?", Choices: []string{"a", "b", "c", "d", "e"}, Answer: 4, Explanation: "was → were"},
+		{Number: 3, Prompt: "빈칸에 알맞은 말을 고르시오.", Context: []string{"The (a) _______ was closed.", "[보기] bank / ran"}, Answer: -1, AnswerText: "(a) bank"},
+	}
+	got, err := parseQuestions(formatQuestions(questions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, questions) {
+		t.Errorf("read back %#v, want %#v", got, questions)
+	}
+}
+
+func TestSplitAnswerKey(t *testing.T) {
+	tests := []struct {
+		text, body, key string
+	}{
+		{"1. q\n\n[정답]\n1. ①\n", "1. q\n", "\n1. ①\n"},
+		{"1. q\n## 정답\n1. ①", "1. q\n", "\n1. ①"},
+		{"1. q", "1. q", ""},
+		// The last header wins, so a header quoted in a question is kept.
+		{"1. [정답]\n[정답]\n1. ①", "1. [정답]\n", "\n1. ①"},
+	}
+	for _, tt := range tests {
+		body, key := splitAnswerKey(tt.text)
+		if body != tt.body || key != tt.key {
+			t.Errorf("splitAnswerKey(%q) = %q, %q, want %q, %q", tt.text, body, key, tt.body, tt.key)
+		}
+	}
+}
+
+func TestAttachSourcesByPartOfSpeech(t *testing.T) {
+	vocab := []VocabPair{
+		{Word: "conduct", POS: "n.", Meanings: []string{"행동"}},
+		{Word: "conduct", POS: "v.", Meanings: []string{"수행하다"}},
+		{Word: "bank", Meanings: []string{"은행", "둑"}},
+	}
+	choices := []string{"conduct", "bank", "fair", "bear", "light"}
+	tests := []struct {
+		name            string
+		q               Question
+		word, pos, note string
+	}{
+		{"note after the answer", Question{Choices: choices, Answer: 0, Explanation: "v."}, "conduct", "v.", ""},
+		{"structured pos", Question{Choices: choices, Answer: 0, POS: "V"}, "conduct", "v.", ""},
+		{"sense", Question{Choices: choices, Answer: 0, Sense: "행동"}, "conduct", "n.", ""},
+		{"other notes are kept", Question{Choices: choices, Answer: 1, Explanation: "강둑"}, "bank", "", "강둑"},
+		{"word in the context", Question{Context: []string{"We conduct a survey."}, Choices: []string{"a", "b", "c", "d", "e"}, Answer: 0, POS: "v."}, "conduct", "v.", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questions := []Question{tt.q}
+			attachSources(questions, vocab)
+			q := questions[0]
+			if q.Word != tt.word || q.POS != tt.pos || q.Explanation != tt.note {
+				t.Errorf("got word %q, pos %q, explanation %q; want %q, %q, %q", q.Word, q.POS, q.Explanation, tt.word, tt.pos, tt.note)
+			}
+		})
+	}
+}
+
+func TestAttachSourcesSpreadsUnmarkedQuestions(t *testing.T) {
+	vocab := []VocabPair{
+		{Word: "conduct", POS: "n.", Meanings: []string{"행동"}},
+		{Word: "conduct", POS: "v.", Meanings: []string{"수행하다"}},
+	}
+	questions := []Question{
+		{Choices: []string{"conduct", "b", "c", "d", "e"}, Answer: 0},
+		{Choices: []string{"conduct", "b", "c", "d", "e"}, Answer: 0},
+	}
+	attachSources(questions, vocab)
+	if questions[0].key() == questions[1].key() {
+		t.Errorf("both questions were given to %q", questions[0].key())
+	}
+	for _, q := range questions {
+		v, ok := findVocab(vocab, q.Word, q.POS)
+		if !ok || q.Sense != v.Meanings[0] {
+			t.Errorf("question for %q has sense %q", headword(v), q.Sense)
+		}
+	}
+}
+
+func TestAttachSourcesPassageWords(t *testing.T) {
+	vocab := []VocabPair{
+		{Word: "conduct", POS: "n.", Meanings: []string{"행동"}},
+		{Word: "conduct", POS: "v.", Meanings: []string{"수행하다"}},
+		{Word: "bank", Meanings: []string{"은행"}},
+	}
+	questions := []Question{{AnswerText: "(a) conduct (b) bank (c) conduct", Answer: -1}}
+	attachSources(questions, vocab)
+	want := []string{"conduct (n.)", "bank", "conduct (v.)"}
+	if !reflect.DeepEqual(questions[0].Words, want) {
+		t.Errorf("Words = %q, want %q", questions[0].Words, want)
+	}
+}
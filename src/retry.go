@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	retryBaseDelay    = 2 * time.Second
+	retryMaxDelay     = 30 * time.Second
+	// maxRetryAfter caps how long we are willing to wait on a server's request.
+	// Anything longer is surfaced as an error instead of freezing the run.
+	maxRetryAfter = 2 * time.Minute
+)
+
+// retryGenerator retries rate-limited and transient failures of the wrapped
+// Generator with exponential backoff and jitter.
+type retryGenerator struct {
+	inner      Generator
+	maxRetries int
+}
+
+func withRetry(inner Generator, maxRetries int) Generator {
+	if maxRetries < 0 {
+		maxRetries = defaultMaxRetries
+	}
+	return &retryGenerator{inner: inner, maxRetries: maxRetries}
+}
+
+func (g *retryGenerator) Generate(ctx context.Context, req GenerateRequest) (string, error) {
+	for attempt := 0; ; attempt++ {
+		text, err := g.inner.Generate(ctx, req)
+		if err == nil {
+			return text, nil
+		}
+
+		var genErr *GenerationError
+		if !errors.As(err, &genErr) || !genErr.Retryable() || attempt >= g.maxRetries {
+			return text, err
+		}
+		wait := backoffDelay(attempt, genErr.RetryAfter)
+		if wait > maxRetryAfter {
+			return text, err
+		}
+
+		if req.OnRetry != nil {
+			req.OnRetry(attempt+1, g.maxRetries, wait, genErr)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoffDelay returns the wait before retry number attempt+1. A server-provided
+// Retry-After is honoured as a floor; jitter keeps parallel requests from retrying in lockstep.
+func backoffDelay(attempt int, retryAfter time.Duration) time.Duration {
+	d := retryMaxDelay
+	// The cap is reached long before the shift could overflow.
+	if attempt < 8 && retryBaseDelay<<attempt < d {
+		d = retryBaseDelay << attempt
+	}
+	d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	if retryAfter > d {
+		d = retryAfter + time.Duration(rand.Int63n(int64(time.Second)))
+	}
+	return d
+}
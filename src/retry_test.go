@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter time.Duration
+		min, max   time.Duration
+	}{
+		{"first retry", 0, 0, time.Second, 2 * time.Second},
+		{"doubles", 1, 0, 2 * time.Second, 4 * time.Second},
+		{"capped", 4, 0, retryMaxDelay / 2, retryMaxDelay},
+		{"many retries", 100, 0, retryMaxDelay / 2, retryMaxDelay},
+		{"retry-after is a floor", 0, 10 * time.Second, 10 * time.Second, 11 * time.Second},
+		{"short retry-after", 2, time.Second, 4 * time.Second, 8 * time.Second},
+		{"retry-after past the cap", 5, time.Minute, time.Minute, time.Minute + time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				if d := backoffDelay(tt.attempt, tt.retryAfter); d < tt.min || d > tt.max {
+					t.Fatalf("backoffDelay(%d, %v) = %v, want within [%v, %v]", tt.attempt, tt.retryAfter, d, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
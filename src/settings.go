@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const maxRecentFiles = 10
+
+// userSettings is the per-user state remembered between sessions. Unlike
+// api.json it lives in the user's config directory, not the working directory.
+type userSettings struct {
+	LastDir     string   `json:"last_dir,omitempty"`
+	RecentFiles []string `json:"recent_files,omitempty"`
+	// Difficulty and Grade are the last level chosen in the generation menu.
+	Difficulty string `json:"difficulty,omitempty"`
+	Grade      int    `json:"grade,omitempty"`
+	// Mix is the last mix of question types, in parseMix syntax.
+	Mix string `json:"mix,omitempty"`
+}
+
+func settingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vocab-maker", "settings.json"), nil
+}
+
+func loadSettings() (userSettings, error) {
+	var s userSettings
+	path, err := settingsPath()
+	if err != nil {
+		return s, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+func saveSettings(s userSettings) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// rememberFile records path as the most recently opened file and its directory
+// as the place the file picker starts next time.
+func (s *userSettings) rememberFile(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	s.LastDir = filepath.Dir(path)
+
+	recent := []string{path}
+	for _, p := range s.RecentFiles {
+		if p != path && len(recent) < maxRecentFiles {
+			recent = append(recent, p)
+		}
+	}
+	s.RecentFiles = recent
+}
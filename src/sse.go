@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// streamTimeout bounds a whole streamed response. Streams legitimately run much
+// longer than a blocking request, since the body keeps arriving while the model writes.
+const streamTimeout = 10 * time.Minute
+
+// readSSE reads a text/event-stream body and calls fn for every complete event.
+// Multi-line data fields are joined with "\n" as the SSE spec requires.
+// Returning io.EOF from fn stops reading without an error.
+func readSSE(r io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		defer func() { event, data = "", nil }()
+		if len(data) == 0 {
+			return nil
+		}
+		return fn(event, strings.Join(data, "\n"))
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := dispatch(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadSSE(t *testing.T) {
+	type event struct{ name, data string }
+	tests := []struct {
+		name    string
+		stream  string
+		stopAt  string // data that makes fn return io.EOF
+		want    []event
+		wantErr string
+	}{
+		{"one event", "data: hello\n\n", "", []event{{"", "hello"}}, ""},
+		{"multi-line data", "data: line 1\ndata:line 2\ndata: \n\n", "", []event{{"", "line 1\nline 2\n"}}, ""},
+		{"comments and keep-alives", ": ping\n\n: ping\ndata: a\n\n", "", []event{{"", "a"}}, ""},
+		{"event names", "event: message_start\ndata: {}\n\nevent: ping\ndata: {}\n\ndata: x\n\n", "", []event{{"message_start", "{}"}, {"ping", "{}"}, {"", "x"}}, ""},
+		{"last event without a blank line", "data: a\n\ndata: b", "", []event{{"", "a"}, {"", "b"}}, ""},
+		{"unknown fields", "id: 1\nretry: 10\ndata: a\n\n", "", []event{{"", "a"}}, ""},
+		{"stop", "data: a\n\ndata: [DONE]\n\ndata: after\n\n", "[DONE]", []event{{"", "a"}, {"", "[DONE]"}}, ""},
+		{"error from fn", "data: a\n\ndata: bad\n\ndata: after\n\n", "", []event{{"", "a"}, {"", "bad"}}, "bad event"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []event
+			err := readSSE(strings.NewReader(tt.stream), func(name, data string) error {
+				got = append(got, event{name, data})
+				switch data {
+				case tt.stopAt:
+					return io.EOF
+				case "bad":
+					return errors.New("bad event")
+				}
+				return nil
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadSSEReadError(t *testing.T) {
+	r := io.MultiReader(strings.NewReader("data: a\n\ndata: b"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	var got []string
+	err := readSSE(r, func(_, data string) error {
+		got = append(got, data)
+		return nil
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("got %q, %v; want only the complete event and the read error", got, err)
+	}
+}
+
+func TestReadProviderStreams(t *testing.T) {
+	openAI := func(s string) string { return `data: {"choices": [{"delta": {"content": "` + s + `"}}]}` + "\n\n" }
+	anthropic := func(s string) string {
+		return "event: content_block_delta\n" + `data: {"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "` + s + `"}}` + "\n\n"
+	}
+	gemini := func(s, finish string) string {
+		return `data: {"candidates": [{"content": {"parts": [{"text": "` + s + `"}], "role": "model"}, "finishReason": "` + finish + `"}]}` + "\r\n\r\n"
+	}
+	const (
+		openAIDone       = "data: [DONE]\n\n"
+		anthropicStart   = "event: message_start\n" + `data: {"type": "message_start", "message": {"id": "msg_1"}}` + "\n\n" + ": keep-alive\n\nevent: ping\ndata: {\"type\": \"ping\"}\n\n"
+		anthropicStop    = "event: message_stop\ndata: {\"type\": \"message_stop\"}\n\n"
+		anthropicDeltaEv = "event: message_delta\n" + `data: {"type": "message_delta", "delta": {"stop_reason": "end_turn"}}` + "\n\n"
+	)
+	readers := map[string]func(io.Reader, func(string)) (string, error){
+		"openai":    readChatGPTStream,
+		"anthropic": readAnthropicStream,
+		"gemini":    readGeminiStream,
+	}
+	tests := []struct {
+		name, provider string
+		stream         string
+		readErr        error // returned after the stream
+		want           string
+		wantErr        string
+	}{
+		{"openai", "openai", ": keep-alive\n\n" + `data: {"choices": [{"delta": {"role": "assistant"}}]}` + "\n\n" + openAI("1. ") + openAI("bank") + openAIDone, nil, "1. bank", ""},
+		{"openai ignores events after done", "openai", openAI("a") + openAIDone + openAI("b"), nil, "a", ""},
+		{"openai error event", "openai", openAI("a") + `data: {"error": {"message": "server overloaded", "type": "server_error"}}` + "\n\n", nil, "a", "API 오류: server overloaded (server_error)"},
+		{"openai cut off", "openai", openAI("a") + openAI("b"), nil, "ab", "unexpected EOF"},
+		{"openai connection lost", "openai", openAI("a") + `data: {"choi`, io.ErrUnexpectedEOF, "a", "unexpected EOF"},
+		{"openai bad json", "openai", "data: {\n\n", nil, "", "스트림 JSON 파싱 오류"},
+		{"openai empty", "openai", openAIDone, nil, "", "API가 비어있는 응답을 반환했습니다"},
+		{"anthropic", "anthropic", anthropicStart + anthropic("1. ") + anthropic("bank") + anthropicDeltaEv + anthropicStop, nil, "1. bank", ""},
+		{"anthropic error event", "anthropic", anthropicStart + anthropic("a") + "event: error\n" + `data: {"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}` + "\n\n", nil, "a", "API 오류: Overloaded (overloaded_error)"},
+		{"anthropic cut off", "anthropic", anthropicStart + anthropic("a"), nil, "a", "unexpected EOF"},
+		{"anthropic connection lost", "anthropic", anthropicStart + anthropic("a") + "event: content_block_delta\ndata: {", io.ErrUnexpectedEOF, "a", "unexpected EOF"},
+		{"gemini", "gemini", gemini("1. ", "") + gemini("bank", "STOP"), nil, "1. bank", ""},
+		{"gemini error event", "gemini", gemini("a", "") + `data: {"error": {"code": 503, "message": "The model is overloaded.", "status": "UNAVAILABLE"}}` + "\n\n", nil, "a", "API 오류: The model is overloaded. (UNAVAILABLE)"},
+		{"gemini cut off", "gemini", gemini("a", "") + gemini("b", ""), nil, "ab", "unexpected EOF"},
+		{"gemini connection lost", "gemini", gemini("a", "") + "data: {", io.ErrUnexpectedEOF, "a", "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r io.Reader = strings.NewReader(tt.stream)
+			if tt.readErr != nil {
+				r = io.MultiReader(r, iotest.ErrReader(tt.readErr))
+			}
+			var deltas []string
+			text, err := readers[tt.provider](r, func(d string) { deltas = append(deltas, d) })
+			if text != tt.want || strings.Join(deltas, "") != tt.want {
+				t.Errorf("text %q, deltas %q, want %q", text, deltas, tt.want)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
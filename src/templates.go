@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// defaultTemplatesDir is where teachers put their own prompt templates, relative
+// to the working directory like api.json.
+const defaultTemplatesDir = "templates"
+
+// commonTemplate holds the blocks shared by every question type.
+const commonTemplate = "_common.tmpl"
+
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+// QuestionType is a question type defined by a prompt template file. The "meta"
+// block of the file sets the fields; the "task", "main_rule" and "structure"
+// blocks become the type-specific part of the system prompt.
+type QuestionType struct {
+	ID          string
+	Description string
+	// AnswerIsWord means the correct choice is the tested word itself ("answer: word").
+	AnswerIsWord bool
+	// PerSense means one question per meaning instead of per word ("coverage: sense").
+	PerSense bool
+	// ExcludeWord means the tested word must not be one of the choices, as in
+	// synonym and antonym questions ("word_in_choices: no").
+	ExcludeWord bool
+	// Blanks means every context sentence must contain a '___' blank ("blanks: yes").
+	Blanks bool
+	// Sentences is the default number of context sentences; 0 if the type does
+	// not ask for a count ("sentences: 2").
+	Sentences int
+	// Passage is the number of words per passage of a passage type ("passage: 5");
+	// 0 for other types. Passage questions are answered blank by blank unless
+	// Underline is set.
+	Passage int
+	// BlankChoices turns the [보기] word bank of a passage into ①–⑤ choices
+	// under every blank ("blank_choices: yes").
+	BlankChoices bool
+	// Underline means each passage marks five parts '①[...]' to '⑤[...]' and the
+	// answer is the one used wrongly ("underline: yes").
+	Underline bool
+
+	// Local builds the questions from the list without a model; nil for
+	// template-defined types.
+	Local localBuilder
+
+	tmpl *template.Template
+}
+
+// promptData is what templates see as '.'.
+type promptData struct {
+	Words      string
+	Sentences  int
+	Difficulty string
+	// Learner describes the target students and DifficultyRules are the rules of
+	// the chosen level; both are empty when Difficulty is.
+	Learner         string
+	DifficultyRules []string
+	Annotated       bool
+	PassageSize     int
+}
+
+// templateFuncs are available to every template.
+var templateFuncs = template.FuncMap{
+	"blankLabel": blankLabel,
+}
+
+// questionTypes is the list offered in the menu, in file name order. It starts
+// with the embedded defaults and is replaced by loadQuestionTypes.
+var questionTypes = mustLoadEmbeddedTypes()
+
+func mustLoadEmbeddedTypes() []QuestionType {
+	sub, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		panic(err)
+	}
+	types, err := parseQuestionTypes(sub, nil, "")
+	if err != nil {
+		panic(fmt.Sprintf("embedded templates: %v", err))
+	}
+	return types
+}
+
+// loadQuestionTypes reads the templates in dir on top of the embedded ones: a
+// file with the same name replaces the default, new files add question types.
+// A missing dir is not an error. Broken files are reported and skipped; the
+// default of the same name, if there is one, is used instead.
+func loadQuestionTypes(dir string) error {
+	if dir == "" {
+		dir = defaultTemplatesDir
+	}
+	sub, _ := fs.Sub(embeddedTemplates, "templates")
+	var override fs.FS
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		override = os.DirFS(dir)
+	}
+	types, err := parseQuestionTypes(sub, override, dir)
+	if len(types) > 0 {
+		questionTypes = types
+	}
+	return err
+}
+
+// templateFile is a template file and the path it is reported by.
+type templateFile struct {
+	fsys fs.FS
+	name string
+	path string
+}
+
+// parseQuestionTypes parses the templates of override, read from dir, on top of
+// defaults. Every file name has the override first and the default second, so
+// an override that fails to parse falls back to the default.
+func parseQuestionTypes(defaults, override fs.FS, dir string) ([]QuestionType, error) {
+	files := make(map[string][]templateFile)
+	for i, fsys := range []fs.FS{override, defaults} {
+		if fsys == nil {
+			continue
+		}
+		names, err := fs.Glob(fsys, "*.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			path := name
+			if i == 0 {
+				path = filepath.Join(dir, name)
+			}
+			files[name] = append(files[name], templateFile{fsys: fsys, name: name, path: path})
+		}
+	}
+
+	var errs []error
+	var base *template.Template
+	for _, f := range files[commonTemplate] {
+		t, err := parseTemplateFile(template.New("").Funcs(templateFuncs), f)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.path, err))
+			continue
+		}
+		base = t
+		break
+	}
+	if base == nil {
+		if len(errs) == 0 {
+			return nil, fmt.Errorf("%s 파일이 없습니다", commonTemplate)
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	var names []string
+	for name := range files {
+		if !strings.HasPrefix(name, "_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var types []QuestionType
+	seen := make(map[string]string)
+	for _, name := range names {
+		for _, f := range files[name] {
+			qt, err := parseQuestionType(base, f)
+			if err == nil && seen[qt.ID] != "" {
+				err = fmt.Errorf("문제 유형 %q이(가) %s에도 있습니다", qt.ID, seen[qt.ID])
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.path, err))
+				continue
+			}
+			seen[qt.ID] = f.path
+			types = append(types, qt)
+			break
+		}
+	}
+	for _, qt := range localQuestionTypes {
+		if seen[qt.ID] == "" {
+			types = append(types, qt)
+		}
+	}
+	return types, errors.Join(errs...)
+}
+
+// parseTemplateFile adds the file to the templates of t under its path, so
+// parse errors name the file they are in.
+func parseTemplateFile(t *template.Template, f templateFile) (*template.Template, error) {
+	data, err := fs.ReadFile(f.fsys, f.name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := t.New(f.path).Parse(string(data)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parseQuestionType parses one type file on top of the common blocks and checks
+// that every block renders.
+func parseQuestionType(base *template.Template, f templateFile) (QuestionType, error) {
+	t, err := base.Clone()
+	if err != nil {
+		return QuestionType{}, err
+	}
+	if t, err = parseTemplateFile(t, f); err != nil {
+		return QuestionType{}, err
+	}
+	qt := QuestionType{tmpl: t}
+
+	sample := promptData{Words: "bank = 은행, 둑", Sentences: 2, PassageSize: 5}
+	sample.Learner, sample.DifficultyRules, _ = LearnerProfile{Difficulty: difficultyLevels[0].ID}.describe()
+	sample.Difficulty = difficultyLevels[0].ID
+	for _, block := range []string{"meta", "role", "style", "task", "main_rule", "structure", "user"} {
+		if t.Lookup(block) == nil {
+			return qt, fmt.Errorf("%q 블록이 없습니다", block)
+		}
+		if _, err := qt.render(block, sample); err != nil {
+			return qt, err
+		}
+	}
+
+	meta, _ := qt.render("meta", sample)
+	for _, line := range strings.Split(meta, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "name":
+			qt.ID = value
+		case "description":
+			qt.Description = value
+		case "answer":
+			qt.AnswerIsWord = value == "word"
+		case "coverage":
+			qt.PerSense = value == "sense"
+		case "word_in_choices":
+			qt.ExcludeWord = value == "no" || value == "false"
+		case "blanks":
+			qt.Blanks = value == "yes" || value == "true"
+		case "sentences":
+			if qt.Sentences, err = strconv.Atoi(value); err != nil {
+				return qt, fmt.Errorf("sentences: %w", err)
+			}
+		case "passage":
+			if qt.Passage, err = strconv.Atoi(value); err != nil || qt.Passage < 1 || qt.Passage > len(blankLetters) {
+				return qt, fmt.Errorf("passage: 1에서 %d 사이의 수여야 합니다", len(blankLetters))
+			}
+		case "blank_choices":
+			qt.BlankChoices = value == "yes" || value == "true"
+		case "underline":
+			qt.Underline = value == "yes" || value == "true"
+		}
+	}
+	if qt.BlankChoices && qt.Passage == 0 {
+		return qt, fmt.Errorf("blank_choices는 passage와 함께 써야 합니다")
+	}
+	if qt.Underline && (qt.Passage == 0 || qt.BlankChoices) {
+		return qt, fmt.Errorf("underline은 passage와 함께, blank_choices 없이 써야 합니다")
+	}
+	if qt.ID == "" {
+		qt.ID = strings.TrimSuffix(f.name, ".tmpl")
+	}
+	return qt, nil
+}
+
+func (qt QuestionType) render(block string, data promptData) (string, error) {
+	if qt.tmpl == nil {
+		return "", fmt.Errorf("알 수 없는 문제 유형입니다")
+	}
+	var b bytes.Buffer
+	if err := qt.tmpl.ExecuteTemplate(&b, block, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// renderLines renders a block and returns its non-empty lines.
+func (qt QuestionType) renderLines(block string, data promptData) ([]string, error) {
+	text, err := qt.render(block, data)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, l := range strings.Split(text, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines, nil
+}
+
+// findQuestionType looks up a question type by its menu name.
+func findQuestionType(id string) (QuestionType, bool) {
+	for _, qt := range questionTypes {
+		if qt.ID == id {
+			return qt, true
+		}
+	}
+	return QuestionType{}, false
+}
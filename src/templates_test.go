@@ -0,0 +1,125 @@
+package main
+
+import (
+	"io/fs"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseQuestionTypes(t *testing.T) {
+	defaults, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedded, err := fs.ReadFile(defaults, "10-blank.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := strings.Replace(string(embedded), "name: 빈칸 추론", "name: 내 빈칸", 1)
+	tests := []struct {
+		name     string
+		override fstest.MapFS
+		ids      []string // must be among the types
+		missing  []string // must not be
+		wantErr  []string // substrings of the error
+	}{
+		{
+			name:     "override replaces the default",
+			override: fstest.MapFS{"10-blank.tmpl": {Data: []byte(renamed)}},
+			ids:      []string{"내 빈칸", "영영풀이"},
+			missing:  []string{"빈칸 추론"},
+		},
+		{
+			name:     "new type",
+			override: fstest.MapFS{"99-mine.tmpl": {Data: []byte(strings.Replace(renamed, "name: 내 빈칸", "name: 새 유형", 1))}},
+			ids:      []string{"빈칸 추론", "새 유형"},
+		},
+		{
+			name:     "broken override falls back to the default",
+			override: fstest.MapFS{"10-blank.tmpl": {Data: []byte(`{{define "meta"}}name: 내 빈칸{{end}}{{define "task"}}{{.Nope}{{end}}`)}},
+			ids:      []string{"빈칸 추론"},
+			missing:  []string{"내 빈칸"},
+			wantErr:  []string{"mine/10-blank.tmpl:", "template: mine/10-blank.tmpl:1:"},
+		},
+		{
+			name:     "override missing a block falls back to the default",
+			override: fstest.MapFS{"10-blank.tmpl": {Data: []byte(`{{define "meta"}}name: 내 빈칸{{end}}`)}},
+			ids:      []string{"빈칸 추론"},
+			wantErr:  []string{`mine/10-blank.tmpl: "task" 블록이 없습니다`},
+		},
+		{
+			name:     "broken common blocks fall back to the default",
+			override: fstest.MapFS{"_common.tmpl": {Data: []byte(`{{define "role"}}`)}},
+			ids:      []string{"빈칸 추론", "영영풀이"},
+			wantErr:  []string{"mine/_common.tmpl: template: mine/_common.tmpl:1:"},
+		},
+		{
+			name:     "broken new type is skipped",
+			override: fstest.MapFS{"99-mine.tmpl": {Data: []byte(`{{define "meta"}}name: 새 유형{{end}}`)}},
+			ids:      []string{"빈칸 추론"},
+			missing:  []string{"새 유형"},
+			wantErr:  []string{"mine/99-mine.tmpl"},
+		},
+		{
+			name:     "duplicate name",
+			override: fstest.MapFS{"99-mine.tmpl": {Data: embedded}},
+			ids:      []string{"빈칸 추론"},
+			wantErr:  []string{`mine/99-mine.tmpl: 문제 유형 "빈칸 추론"이(가) 10-blank.tmpl에도 있습니다`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			types, err := parseQuestionTypes(defaults, tt.override, "mine")
+			ids := make(map[string]bool)
+			for _, qt := range types {
+				ids[qt.ID] = true
+			}
+			for _, id := range tt.ids {
+				if !ids[id] {
+					t.Errorf("type %q is missing", id)
+				}
+			}
+			for _, id := range tt.missing {
+				if ids[id] {
+					t.Errorf("type %q should not be there", id)
+				}
+			}
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			for _, want := range tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPromptsReportsRenderErrors(t *testing.T) {
+	defaults, _ := fs.Sub(embeddedTemplates, "templates")
+	common, err := fs.ReadFile(defaults, commonTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The check at load time renders with a level set; this role fails without one.
+	role := `{{define "role"}}{{if .Difficulty}}ok{{else}}{{index .DifficultyRules 0}}{{end}}{{end}}`
+	broken := regexp.MustCompile(`\{\{define "role"\}\}.*?\{\{end\}\}`).ReplaceAllLiteralString(string(common), role)
+	if broken == string(common) {
+		t.Fatal("no role block in the embedded common template")
+	}
+	override := fstest.MapFS{commonTemplate: {Data: []byte(broken)}}
+	types, err := parseQuestionTypes(defaults, override, "mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := questionTypes
+	questionTypes = types
+	defer func() { questionTypes = saved }()
+
+	if _, _, err := buildPrompts([]VocabPair{{Word: "bank", Meanings: []string{"은행"}}}, "영영풀이", 0, LearnerProfile{}, false); err == nil || !strings.Contains(err.Error(), "'영영풀이' 프롬프트 만들기 실패") {
+		t.Errorf("error = %v", err)
+	}
+}
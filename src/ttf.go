@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"unicode"
+	"unicode/utf16"
+)
+
+// ttfFont is a TrueType font read for embedding in a PDF. Only what the PDF
+// writer needs is parsed: metrics, the character map and the glyph outlines.
+type ttfFont struct {
+	tables     map[string][]byte
+	name       string // PostScript name
+	unitsPerEm int
+	bbox       [4]int
+	ascent     int
+	descent    int
+	capHeight  int
+	advances   []int // per glyph, in font units
+	cmap       map[rune]uint16
+	loca       []int // offsets into glyf, one more than there are glyphs
+}
+
+func loadTTF(path string) (*ttfFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseTTF(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// parseTTF reads a TrueType font, or the first font of a TrueType collection.
+func parseTTF(data []byte) (f *ttfFont, err error) {
+	// Every read below is bounds-checked by the slice; a truncated font panics.
+	defer func() {
+		if recover() != nil {
+			f, err = nil, errors.New("글꼴 파일이 손상되었습니다")
+		}
+	}()
+
+	dir := 0
+	switch string(data[:4]) {
+	case "ttcf":
+		dir = int(u32(data, 12))
+	case "OTTO":
+		return nil, errors.New("CFF 윤곽선(OTF) 글꼴은 지원하지 않습니다. TrueType(.ttf) 글꼴을 지정하세요")
+	case "\x00\x01\x00\x00", "true":
+	default:
+		return nil, errors.New("TrueType 글꼴이 아닙니다")
+	}
+
+	f = &ttfFont{tables: make(map[string][]byte)}
+	numTables := int(u16(data, dir+4))
+	for i := 0; i < numTables; i++ {
+		rec := dir + 12 + 16*i
+		off, length := int(u32(data, rec+8)), int(u32(data, rec+12))
+		f.tables[string(data[rec:rec+4])] = data[off : off+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap", "loca", "glyf"} {
+		if f.tables[tag] == nil {
+			return nil, fmt.Errorf("글꼴에 %s 테이블이 없습니다", tag)
+		}
+	}
+
+	head := f.tables["head"]
+	f.unitsPerEm = int(u16(head, 18))
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("글꼴의 head 테이블이 잘못되었습니다")
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(u16(head, 36+2*i)))
+	}
+	hhea := f.tables["hhea"]
+	f.ascent = int(int16(u16(hhea, 4)))
+	f.descent = int(int16(u16(hhea, 6)))
+	f.capHeight = f.ascent
+	if os2 := f.tables["OS/2"]; len(os2) >= 90 && u16(os2, 0) >= 2 {
+		f.capHeight = int(int16(u16(os2, 88)))
+	}
+
+	numGlyphs := int(u16(f.tables["maxp"], 4))
+	hmtx := f.tables["hmtx"]
+	numMetrics := int(u16(hhea, 34))
+	if numGlyphs == 0 || numMetrics == 0 || numMetrics > numGlyphs {
+		return nil, errors.New("글꼴의 글리프 수가 잘못되었습니다")
+	}
+	f.advances = make([]int, numGlyphs)
+	for g := range f.advances {
+		f.advances[g] = int(u16(hmtx, 4*min(g, numMetrics-1)))
+	}
+
+	loca := f.tables["loca"]
+	f.loca = make([]int, numGlyphs+1)
+	for g := range f.loca {
+		if int16(u16(head, 50)) == 0 {
+			f.loca[g] = 2 * int(u16(loca, 2*g))
+		} else {
+			f.loca[g] = int(u32(loca, 4*g))
+		}
+	}
+	// subset and components slice glyf with these offsets.
+	glyf := f.tables["glyf"]
+	for g := 0; g < numGlyphs; g++ {
+		if f.loca[g] > f.loca[g+1] || f.loca[g+1] > len(glyf) {
+			return nil, fmt.Errorf("글꼴의 loca 테이블이 잘못되었습니다 (글리프 %d)", g)
+		}
+	}
+
+	if f.cmap, err = parseCmap(f.tables["cmap"]); err != nil {
+		return nil, err
+	}
+	for r, gid := range f.cmap {
+		if int(gid) >= numGlyphs {
+			// A character mapped past the last glyph has no glyph.
+			delete(f.cmap, r)
+		}
+	}
+	f.name = postScriptName(f.tables["name"])
+	return f, nil
+}
+
+// parseCmap reads the Unicode subtable of a cmap, format 12 or 4.
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	var format4, format12 []byte
+	for i := 0; i < int(u16(cmap, 2)); i++ {
+		rec := 4 + 8*i
+		platform, encoding := u16(cmap, rec), u16(cmap, rec+2)
+		sub := cmap[u32(cmap, rec+4):]
+		unicode := platform == 0 || platform == 3 && (encoding == 1 || encoding == 10)
+		switch {
+		case !unicode:
+		case u16(sub, 0) == 12:
+			format12 = sub
+		case u16(sub, 0) == 4:
+			format4 = sub
+		}
+	}
+
+	m := make(map[rune]uint16)
+	switch {
+	case format12 != nil:
+		for i := 0; i < int(u32(format12, 12)); i++ {
+			g := 16 + 12*i
+			start, end, gid := u32(format12, g), u32(format12, g+4), u32(format12, g+8)
+			for c := start; c <= end && c <= 0x10FFFF; c++ {
+				m[rune(c)] = uint16(gid + c - start)
+			}
+		}
+	case format4 != nil:
+		segs := int(u16(format4, 6)) / 2
+		ends, starts := 14, 16+2*segs
+		deltas, ranges := starts+2*segs, starts+4*segs
+		for s := 0; s < segs; s++ {
+			start, end := int(u16(format4, starts+2*s)), int(u16(format4, ends+2*s))
+			delta, rangeOff := u16(format4, deltas+2*s), int(u16(format4, ranges+2*s))
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				gid := uint16(c) + delta
+				if rangeOff != 0 {
+					gid = u16(format4, ranges+2*s+rangeOff+2*(c-start))
+					if gid != 0 {
+						gid += delta
+					}
+				}
+				if gid != 0 {
+					m[rune(c)] = gid
+				}
+			}
+		}
+	default:
+		return nil, errors.New("글꼴에 유니코드 문자표(cmap)가 없습니다")
+	}
+	return m, nil
+}
+
+// postScriptName returns name ID 6 of the name table, or "Font".
+func postScriptName(name []byte) string {
+	if name == nil {
+		return "Font"
+	}
+	count, storage := int(u16(name, 2)), int(u16(name, 4))
+	for i := 0; i < count; i++ {
+		rec := 6 + 12*i
+		platform, id := u16(name, rec), u16(name, rec+6)
+		length, off := int(u16(name, rec+8)), storage+int(u16(name, rec+10))
+		if id != 6 {
+			continue
+		}
+		raw := name[off : off+length]
+		if platform == 3 || platform == 0 {
+			units := make([]uint16, len(raw)/2)
+			for j := range units {
+				units[j] = u16(raw, 2*j)
+			}
+			raw = []byte(string(utf16.Decode(units)))
+		}
+		var b []byte
+		for _, c := range raw {
+			if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' {
+				b = append(b, c)
+			}
+		}
+		if len(b) > 0 {
+			return string(b)
+		}
+	}
+	return "Font"
+}
+
+// glyph returns the glyph of r, or ok false if the font has none.
+func (f *ttfFont) glyph(r rune) (gid uint16, ok bool) {
+	gid, ok = f.cmap[r]
+	return gid, ok
+}
+
+// missing returns the characters of s, other than spaces and control
+// characters, that the font has no glyph for, each once.
+func (f *ttfFont) missing(s string) []rune {
+	var out []rune
+	seen := make(map[rune]bool)
+	for _, r := range s {
+		if seen[r] || unicode.IsSpace(r) || unicode.IsControl(r) {
+			continue
+		}
+		seen[r] = true
+		if _, ok := f.glyph(r); !ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// width returns the advance of s at size points.
+func (f *ttfFont) width(s string, size float64) float64 {
+	units := 0
+	for _, r := range s {
+		gid, _ := f.glyph(r)
+		units += f.advances[gid]
+	}
+	return float64(units) * size / float64(f.unitsPerEm)
+}
+
+// scale converts font units to the 1000-unit glyph space of PDF.
+func (f *ttfFont) scale(units int) int {
+	return units * 1000 / f.unitsPerEm
+}
+
+// subset returns a font file with the outlines of the used glyphs only. Glyph
+// ids are kept, so the file works with an identity CID-to-glyph map; unused
+// glyphs are left empty.
+func (f *ttfFont) subset(used map[uint16]bool) []byte {
+	glyf := f.tables["glyf"]
+	keep := map[uint16]bool{0: true}
+	queue := []uint16{0}
+	for g := range used {
+		queue = append(queue, g)
+	}
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+		keep[g] = true
+		for _, c := range f.components(g) {
+			if !keep[c] {
+				keep[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	var outlines bytes.Buffer
+	loca := make([]byte, 4*len(f.loca))
+	for g := 0; g < len(f.loca)-1; g++ {
+		binary.BigEndian.PutUint32(loca[4*g:], uint32(outlines.Len()))
+		if keep[uint16(g)] {
+			outlines.Write(glyf[f.loca[g]:f.loca[g+1]])
+			for outlines.Len()%4 != 0 {
+				outlines.WriteByte(0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*(len(f.loca)-1):], uint32(outlines.Len()))
+
+	head := append([]byte(nil), f.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)  // checkSumAdjustment, set below
+	binary.BigEndian.PutUint16(head[50:], 1) // long loca offsets
+
+	tables := map[string][]byte{
+		"head": head, "hhea": f.tables["hhea"], "maxp": f.tables["maxp"], "hmtx": f.tables["hmtx"],
+		"loca": loca, "glyf": outlines.Bytes(),
+	}
+	// The hinting programs are kept so the glyphs render as they were designed;
+	// some readers refuse a font without cmap, OS/2 and post, although the PDF
+	// does not use them.
+	for _, tag := range []string{"cvt ", "fpgm", "prep", "cmap", "OS/2"} {
+		if t := f.tables[tag]; t != nil {
+			tables[tag] = t
+		}
+	}
+	if post := f.tables["post"]; len(post) >= 32 {
+		// Version 3 has no glyph names, which can be most of the table.
+		post = append([]byte(nil), post[:32]...)
+		binary.BigEndian.PutUint32(post, 0x00030000)
+		tables["post"] = post
+	}
+	out := writeSFNT(tables)
+	adjust := 0xB1B0AFBA - ttfChecksum(out)
+	for i := 0; i < len(tables); i++ {
+		if rec := 12 + 16*i; string(out[rec:rec+4]) == "head" {
+			binary.BigEndian.PutUint32(out[u32(out, rec+8)+8:], adjust)
+		}
+	}
+	return out
+}
+
+// components returns the glyphs a composite glyph is made of. A truncated
+// glyph, or a component past the last glyph, ends the list.
+func (f *ttfFont) components(g uint16) []uint16 {
+	if int(g) >= len(f.advances) {
+		return nil
+	}
+	data := f.tables["glyf"][f.loca[g]:f.loca[g+1]]
+	if len(data) < 10 || int16(u16(data, 0)) >= 0 {
+		return nil
+	}
+	const (
+		argsAreWords = 0x0001
+		haveScale    = 0x0008
+		moreParts    = 0x0020
+		haveXYScale  = 0x0040
+		have2x2      = 0x0080
+	)
+	var parts []uint16
+	for at := 10; at+4 <= len(data); {
+		flags, part := u16(data, at), u16(data, at+2)
+		if int(part) >= len(f.advances) {
+			return parts
+		}
+		parts = append(parts, part)
+		at += 4
+		if flags&argsAreWords != 0 {
+			at += 4
+		} else {
+			at += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			at += 2
+		case flags&haveXYScale != 0:
+			at += 4
+		case flags&have2x2 != 0:
+			at += 8
+		}
+		if flags&moreParts == 0 {
+			return parts
+		}
+	}
+	return parts
+}
+
+// writeSFNT assembles a font file from its tables.
+func writeSFNT(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	n := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= n {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	var out bytes.Buffer
+	header := make([]byte, 12+16*n)
+	binary.BigEndian.PutUint32(header[0:], 0x00010000)
+	binary.BigEndian.PutUint16(header[4:], uint16(n))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(16*n-searchRange))
+	off := len(header)
+	for i, tag := range tags {
+		t := tables[tag]
+		rec := header[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], ttfChecksum(t))
+		binary.BigEndian.PutUint32(rec[8:], uint32(off))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(t)))
+		off += (len(t) + 3) &^ 3
+	}
+	out.Write(header)
+	for _, tag := range tags {
+		out.Write(tables[tag])
+		for out.Len()%4 != 0 {
+			out.WriteByte(0)
+		}
+	}
+	return out.Bytes()
+}
+
+func ttfChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+func u16(b []byte, at int) uint16 { return binary.BigEndian.Uint16(b[at:]) }
+func u32(b []byte, at int) uint32 { return binary.BigEndian.Uint32(b[at:]) }
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testFontOptions change the font built by buildTestFont.
+type testFontOptions struct {
+	longLoca bool
+	// composite makes the last character's glyph a composite of the first's.
+	composite bool
+	// extraCmap maps a character to a glyph past the last one.
+	extraCmap rune
+}
+
+// buildTestFont returns a TrueType font with a glyph for each character of
+// chars, numbered from 1 in code point order. ASCII glyphs are 500 units wide,
+// the others 1000.
+func buildTestFont(chars string, opt testFontOptions) []byte {
+	seen := make(map[rune]bool)
+	var runes []rune
+	for _, r := range chars {
+		if !seen[r] {
+			seen[r] = true
+			runes = append(runes, r)
+		}
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	numGlyphs := len(runes) + 1
+
+	put16 := func(b []byte, at int, v int) { binary.BigEndian.PutUint16(b[at:], uint16(v)) }
+
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint32(head[0:], 0x00010000)
+	binary.BigEndian.PutUint32(head[12:], 0x5F0F3CF5)
+	put16(head, 18, 1000)
+	put16(head, 36, 0)
+	put16(head, 38, -200)
+	put16(head, 40, 1000)
+	put16(head, 42, 800)
+	if opt.longLoca {
+		put16(head, 50, 1)
+	}
+
+	hhea := make([]byte, 36)
+	binary.BigEndian.PutUint32(hhea[0:], 0x00010000)
+	put16(hhea, 4, 800)
+	put16(hhea, 6, -200)
+	put16(hhea, 34, numGlyphs)
+
+	maxp := make([]byte, 6)
+	binary.BigEndian.PutUint32(maxp[0:], 0x00005000)
+	put16(maxp, 4, numGlyphs)
+
+	hmtx := make([]byte, 4*numGlyphs)
+	put16(hmtx, 0, 500)
+	for i, r := range runes {
+		width := 1000
+		if r < 0x80 {
+			width = 500
+		}
+		put16(hmtx, 4*(i+1), width)
+	}
+
+	// Simple glyphs are a header and one point; the outline itself is never read.
+	var glyf []byte
+	offsets := []int{0, 0} // glyph 0 is empty
+	for i := range runes {
+		g := make([]byte, 14)
+		put16(g, 0, 1)
+		g[10] = byte(i) // tell the glyphs apart
+		if opt.composite && i == len(runes)-1 && i > 0 {
+			g = make([]byte, 16)
+			put16(g, 0, -1)
+			put16(g, 10, 0) // flags: byte arguments, no more parts
+			put16(g, 12, 1) // glyph 1
+		}
+		glyf = append(glyf, g...)
+		offsets = append(offsets, len(glyf))
+	}
+	var loca []byte
+	for _, off := range offsets {
+		if opt.longLoca {
+			loca = binary.BigEndian.AppendUint32(loca, uint32(off))
+		} else {
+			loca = binary.BigEndian.AppendUint16(loca, uint16(off/2))
+		}
+	}
+
+	// A format 4 cmap with one segment per character and the final 0xFFFF one.
+	type segment struct{ start, end, delta int }
+	var segs []segment
+	for i, r := range runes {
+		segs = append(segs, segment{int(r), int(r), i + 1 - int(r)})
+	}
+	if opt.extraCmap != 0 {
+		segs = append(segs, segment{int(opt.extraCmap), int(opt.extraCmap), numGlyphs + 5 - int(opt.extraCmap)})
+		sort.Slice(segs, func(i, j int) bool { return segs[i].start < segs[j].start })
+	}
+	segs = append(segs, segment{0xFFFF, 0xFFFF, 1})
+	n := len(segs)
+	sub := make([]byte, 16+8*n)
+	put16(sub, 0, 4)
+	put16(sub, 2, len(sub))
+	put16(sub, 6, 2*n)
+	for i, s := range segs {
+		put16(sub, 14+2*i, s.end)
+		put16(sub, 16+2*n+2*i, s.start)
+		put16(sub, 16+4*n+2*i, s.delta)
+	}
+	cmap := make([]byte, 12)
+	put16(cmap, 2, 1)
+	put16(cmap, 4, 3)
+	put16(cmap, 6, 1)
+	binary.BigEndian.PutUint32(cmap[8:], 12)
+	cmap = append(cmap, sub...)
+
+	// name ID 6 in UTF-16.
+	psName := "Test-Font"
+	name := make([]byte, 18)
+	put16(name, 2, 1)
+	put16(name, 4, 18)
+	put16(name, 6, 3)
+	put16(name, 8, 1)
+	put16(name, 12, 6)
+	put16(name, 14, 2*len(psName))
+	for _, c := range psName {
+		name = binary.BigEndian.AppendUint16(name, uint16(c))
+	}
+
+	return writeSFNT(map[string][]byte{
+		"head": head, "hhea": hhea, "maxp": maxp, "hmtx": hmtx,
+		"loca": loca, "glyf": glyf, "cmap": cmap, "name": name,
+	})
+}
+
+// writeTestFont saves a test font covering chars and returns its path.
+func writeTestFont(t *testing.T, chars string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.ttf")
+	if err := os.WriteFile(path, buildTestFont(chars, testFontOptions{}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// setTableLength overwrites the length of a table in the font's directory.
+func setTableLength(font []byte, tag string, length int) []byte {
+	font = append([]byte(nil), font...)
+	for i := 0; i < int(u16(font, 4)); i++ {
+		rec := 12 + 16*i
+		if string(font[rec:rec+4]) == tag {
+			binary.BigEndian.PutUint32(font[rec+12:], uint32(length))
+		}
+	}
+	return font
+}
+
+// collectionOf wraps a font in a TrueType collection of one font.
+func collectionOf(font []byte) []byte {
+	const header = 16
+	out := append([]byte("ttcf\x00\x01\x00\x00\x00\x00\x00\x01\x00\x00\x00\x10"), font...)
+	for i := 0; i < int(u16(font, 4)); i++ {
+		rec := header + 12 + 16*i
+		binary.BigEndian.PutUint32(out[rec+8:], u32(out, rec+8)+header)
+	}
+	return out
+}
+
+func TestParseTTF(t *testing.T) {
+	valid := buildTestFont("Ab가", testFontOptions{})
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"short loca", valid, ""},
+		{"long loca", buildTestFont("Ab가", testFontOptions{longLoca: true}), ""},
+		{"collection", collectionOf(valid), ""},
+		{"truncated", valid[:40], "글꼴 파일이 손상되었습니다"},
+		{"not a font", []byte("%PDF-1.7 not a font"), "TrueType 글꼴이 아닙니다"},
+		{"cff", append([]byte("OTTO"), valid[4:]...), "CFF 윤곽선(OTF) 글꼴은 지원하지 않습니다"},
+		{"loca past glyf", setTableLength(valid, "glyf", 20), "글꼴의 loca 테이블이 잘못되었습니다"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseTTF(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[rune]uint16{'A': 1, 'b': 2, '가': 3}
+			if !reflect.DeepEqual(f.cmap, want) {
+				t.Errorf("cmap = %v, want %v", f.cmap, want)
+			}
+			if f.name != "Test-Font" || f.unitsPerEm != 1000 || f.ascent != 800 || f.descent != -200 {
+				t.Errorf("name %q, units %d, ascent %d, descent %d", f.name, f.unitsPerEm, f.ascent, f.descent)
+			}
+			if got := f.width("Ab가", 10); got != 20 {
+				t.Errorf("width = %v, want 20", got)
+			}
+		})
+	}
+}
+
+func TestParseTTFDropsGlyphsPastTheEnd(t *testing.T) {
+	f, err := parseTTF(buildTestFont("ab", testFontOptions{extraCmap: 'z'}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.glyph('z'); ok {
+		t.Error("'z' maps to a glyph the font does not have")
+	}
+	if got := string(f.missing("a z\n가a")); got != "z가" {
+		t.Errorf("missing = %q, want %q", got, "z가")
+	}
+}
+
+func TestSubset(t *testing.T) {
+	tests := []struct {
+		name     string
+		used     []uint16
+		outlines []uint16 // glyphs with an outline in the subset; glyph 0 has none
+	}{
+		{"simple glyph", []uint16{2}, []uint16{2}},
+		{"composite keeps its parts", []uint16{4}, []uint16{1, 4}},
+		{"nothing", nil, []uint16{}},
+	}
+	f, err := parseTTF(buildTestFont("abcd", testFontOptions{composite: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := make(map[uint16]bool)
+			for _, g := range tt.used {
+				used[g] = true
+			}
+			data := f.subset(used)
+			if sum := ttfChecksum(data); sum != 0xB1B0AFBA {
+				t.Errorf("file checksum = %#x, want 0xB1B0AFBA", sum)
+			}
+			sub, err := parseTTF(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(sub.advances, f.advances) || !reflect.DeepEqual(sub.cmap, f.cmap) {
+				t.Error("the subset changed the metrics or the character map")
+			}
+			kept := []uint16{}
+			for g := 0; g < len(sub.loca)-1; g++ {
+				outline := sub.tables["glyf"][sub.loca[g]:sub.loca[g+1]]
+				if len(outline) == 0 {
+					continue
+				}
+				kept = append(kept, uint16(g))
+				if want := f.tables["glyf"][f.loca[g]:f.loca[g+1]]; !strings.HasPrefix(string(outline), string(want)) {
+					t.Errorf("glyph %d changed", g)
+				}
+			}
+			if !reflect.DeepEqual(kept, tt.outlines) {
+				t.Errorf("glyphs with outlines %v, want %v", kept, tt.outlines)
+			}
+		})
+	}
+}
+
+func TestSubsetChecksumAdjustment(t *testing.T) {
+	f, err := parseTTF(buildTestFont("ab", testFontOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The checksum of this table spells "head" in the directory, ahead of the head record.
+	f.tables["cvt "] = []byte("head")
+	data := f.subset(map[uint16]bool{1: true})
+	if sum := ttfChecksum(data); sum != 0xB1B0AFBA {
+		t.Errorf("file checksum = %#x, want 0xB1B0AFBA", sum)
+	}
+	sub, err := parseTTF(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(sub.tables["cvt "]) != "head" {
+		t.Errorf("cvt table = %q", sub.tables["cvt "])
+	}
+}
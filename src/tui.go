@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -17,6 +18,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // --- Enums & Types ---
@@ -26,19 +28,29 @@ type sessionState int
 const (
 	stateDefault sessionState = iota
 	stateFilePicker
+	stateSelectRecent
 	stateSaveFilepath
+	stateSelectProvider
 	stateSelectModel
 	stateSelectQType
+	stateSelectDifficulty
+	stateSelectGrade
+	stateEnterMix
 	stateEnterSentences
+	stateEnterForms
 )
 
 type (
 	fileReadMsg         struct{ content []byte; path string }
-	fileWriteMsg        struct{ path string; err error }
-	generationResultMsg struct{ text string; err error }
+	fileWriteMsg        struct{ path, keyPath string; err error }
+	generationResultMsg struct{ id int; result GenerationResult; err error }
+	generationDeltaMsg  struct{ id int; text string; ch <-chan tea.Msg }
+	generationRetryMsg  struct{ id, attempt, maxRetries int; wait time.Duration; err error; ch <-chan tea.Msg }
+	generationProgressMsg struct{ id, done, total int; ch <-chan tea.Msg }
+	generationRegenMsg    struct{ id, round int; words []VocabPair; ch <-chan tea.Msg }
 	resetStatusMsg      struct{}
 	debugFileWrittenMsg struct{ err error }
-	tickMsg             struct{}
+	tickMsg             struct{ id int }
 	errMsg              struct{ err error }
 )
 
@@ -63,22 +75,78 @@ func readFileCmd(path string) tea.Cmd {
 	}
 }
 
-func writeFileCmd(path, content string) tea.Cmd {
+// writePaperCmd saves the student paper and, if there is one, the answer key
+// to its own file next to it, as text, HTML or PDF by the extension of path.
+func writePaperCmd(path, paper, key string, cfg PrintConfig) tea.Cmd {
 	return func() tea.Msg {
-		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		data, err := renderDocument(path, paper, cfg)
+		if err != nil {
+			return errMsg{err}
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return errMsg{err}
+		}
+		if key == "" {
+			return fileWriteMsg{path: path}
+		}
+		keyPath := answerKeyPath(path)
+		if data, err = renderDocument(keyPath, key, cfg); err != nil {
+			return errMsg{err}
+		}
+		if err := ioutil.WriteFile(keyPath, data, 0644); err != nil {
 			return errMsg{err}
 		}
-		return fileWriteMsg{path: path}
+		return fileWriteMsg{path: path, keyPath: keyPath}
 	}
 }
 
-func generateCmd(apiKey, modelID, systemPrompt, userPrompt string) tea.Cmd {
+// generateCmd runs the job in the background. Streamed text, retries and chunk
+// progress are delivered as messages and the run ends with a generationResultMsg.
+// Messages are tagged with id so that output from a cancelled run can be discarded.
+func generateCmd(ctx context.Context, id int, gen Generator, job GenerationJob) tea.Cmd {
 	return func() tea.Msg {
-		output, err := callChatGPT(apiKey, modelID, systemPrompt, userPrompt)
-		if err != nil {
-			return generationResultMsg{err: err}
+		ch := make(chan tea.Msg, 64)
+		job.OnDelta = func(delta string) {
+			select {
+			case ch <- generationDeltaMsg{id: id, text: delta, ch: ch}:
+			case <-ctx.Done():
+			}
+		}
+		job.OnRetry = func(attempt, maxRetries int, wait time.Duration, err *GenerationError) {
+			select {
+			case ch <- generationRetryMsg{id: id, attempt: attempt, maxRetries: maxRetries, wait: wait, err: err, ch: ch}:
+			case <-ctx.Done():
+			}
+		}
+		job.OnProgress = func(done, total int) {
+			select {
+			case ch <- generationProgressMsg{id: id, done: done, total: total, ch: ch}:
+			case <-ctx.Done():
+			}
 		}
-		return generationResultMsg{text: output}
+		job.OnRegenerate = func(round int, words []VocabPair) {
+			select {
+			case ch <- generationRegenMsg{id: id, round: round, words: words, ch: ch}:
+			case <-ctx.Done():
+			}
+		}
+		go func() {
+			defer close(ch)
+			result, err := runGeneration(ctx, gen, job)
+			if err != nil {
+				ch <- generationResultMsg{id: id, err: err}
+				return
+			}
+			ch <- generationResultMsg{id: id, result: result}
+		}()
+		return <-ch
+	}
+}
+
+// waitForGenerationCmd waits for the next message of a running generation.
+func waitForGenerationCmd(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
 	}
 }
 
@@ -94,9 +162,9 @@ func resetErrorStatusCmd() tea.Cmd {
 	})
 }
 
-func startGenerationTickerCmd() tea.Cmd {
+func startGenerationTickerCmd(id int) tea.Cmd {
 	return tea.Tick(1*time.Second, func(t time.Time) tea.Msg {
-		return tickMsg{}
+		return tickMsg{id: id}
 	})
 }
 
@@ -109,6 +177,9 @@ var (
 	helpStyle          = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	cursorLineNumberStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("255")) // White
 	lineNumberStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240")) // Dark Gray
+	diagnosticStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("214")) // Orange
+	diagnosticErrorLine   = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("196")) // Red
+	diagnosticWarningLine = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("214")) // Orange
 )
 
 
@@ -125,21 +196,37 @@ type model struct {
 	focused    int
 	pathInput  textinput.Model
 	numInput   textinput.Model
+	mixInput   textinput.Model
 	list       list.Model
 	filepicker filepicker.Model
+	panelHeight int
 
 	// Content
 	inputFilePath string
-	apiKey        string
+	config        APIConfig
+	settings      userSettings
+	vocab         []VocabPair
+	questions     []Question
+	forms         []TestForm
+	numForms      string
 
 	// Generation Parameters
+	selectedProvider  string
 	selectedModel     string
 	selectedQType     string
+	mix               []MixPart
+	learner           LearnerProfile
 	numSentences      string
 	generationSeconds int
 
 	// State
-	isGenerating bool
+	isGenerating     bool
+	generationID     int
+	cancelGeneration context.CancelFunc
+	retryStatus      string
+	progressStatus   string
+	// streamStale is set by a retry; the next streamed text replaces the partial stream.
+	streamStale      bool
 	mouseEnabled      bool
 
 	// Hidden Debug
@@ -157,19 +244,19 @@ const (
 )
 
 func initialModel() model {
-	apiKey, err := loadAPIKey()
+	config, err := loadAPIConfig()
 	if err != nil {
-		log.Printf("Failed to load API key: %v", err)
+		log.Printf("Failed to load API config: %v", err)
 	}
 
-	defaultStatus := "F12: Toggle Mouse | Ctrl+O: Load | Ctrl+S: Save | Ctrl+G: Generate | Tab: Switch Panes"
+	defaultStatus := "F12: Toggle Mouse | Ctrl+O: Load | Ctrl+R: Recent | Ctrl+S: Save | Ctrl+G: Generate | Ctrl+F: Forms | Tab: Switch Panes"
 	m := model{
 		state:         stateDefault,
 		status:        defaultStatus,
 		defaultStatus: defaultStatus,
 		inputs:        make([]textarea.Model, 2),
 		focused:       0,
-		apiKey:        apiKey,
+		config:        config,
 		numSentences:  "2",
 		mouseEnabled:  true,
 	}
@@ -207,26 +294,52 @@ m.numInput = textinput.New()
 	m.numInput.CharLimit = 2
 	m.numInput.Width = 5
 
+	m.mixInput = textinput.New()
+	m.mixInput.Placeholder = defaultMixes[false]
+	m.mixInput.CharLimit = 256
+	m.mixInput.Width = 80
+
 	// List
 	m.list = list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
 	m.list.SetShowHelp(false)
 
-		fp := filepicker.New()
-		fp.AllowedTypes = []string{".txt"}
-		wd, err := os.Getwd()
-		if err != nil {
-			// Fallback to root on error
-			fp.CurrentDirectory = "/"
-		} else {
-			fp.CurrentDirectory = wd
-		}
-		m.filepicker = fp
+	settings, err := loadSettings()
+	if err != nil {
+		log.Printf("Failed to load settings: %v", err)
+	}
+	m.settings = settings
+
+	if err := loadQuestionTypes(config.TemplatesDir); err != nil {
+		log.Printf("Failed to load prompt templates: %v", err)
+	}
 
 	return m
 }
 
+// vocabFileExtensions are the file types offered by the Ctrl+O file picker.
+var vocabFileExtensions = importerExtensions()
+
+// newFilePicker creates a picker rooted at the last used directory, falling
+// back to the working directory and then to the filesystem root.
+func (m *model) newFilePicker() filepicker.Model {
+	fp := filepicker.New()
+	fp.AllowedTypes = vocabFileExtensions
+	fp.AutoHeight = false
+	fp.SetHeight(max(m.panelHeight, 5))
+
+	if info, err := os.Stat(m.settings.LastDir); err == nil && info.IsDir() {
+		fp.CurrentDirectory = m.settings.LastDir
+	} else if wd, err := os.Getwd(); err == nil {
+		fp.CurrentDirectory = wd
+	} else {
+		// Fallback to root on error
+		fp.CurrentDirectory = "/"
+	}
+	return fp
+}
+
 func (m *model) Init() tea.Cmd {
-	return tea.Batch(textarea.Blink, m.filepicker.Init(), func() tea.Msg { return tea.EnableMouseCellMotion() })
+	return tea.Batch(textarea.Blink, func() tea.Msg { return tea.EnableMouseCellMotion() })
 }
 
 // --- Update ---
@@ -234,6 +347,18 @@ func (m *model) Init() tea.Cmd {
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Log every message
 	switch msg := msg.(type) {
+	case generationDeltaMsg:
+		// Deltas are too frequent to log individually
+	case generationRetryMsg:
+		// Logged with its error text when handled below
+	case generationRegenMsg:
+		var words []string
+		for _, v := range msg.words {
+			words = append(words, headword(v))
+		}
+		m.logBuffer.WriteString(fmt.Sprintf("[%s] Regeneration round %d: %s\n", time.Now().Format(time.RFC3339), msg.round, strings.Join(words, ", ")))
+	case generationProgressMsg:
+		m.logBuffer.WriteString(fmt.Sprintf("[%s] Chunk progress: %d/%d\n", time.Now().Format(time.RFC3339), msg.done, msg.total))
 	case generationResultMsg:
 		if msg.err != nil {
 			m.logBuffer.WriteString(fmt.Sprintf("[%s] Received message: generationResultMsg with ERROR: %s\n", time.Now().Format(time.RFC3339), msg.err.Error()))
@@ -258,7 +383,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.inputs[i].SetHeight(panelHeight)
 		}
 		m.list.SetSize(listWidth, panelHeight)
-		m.filepicker.Height = panelHeight
+		m.filepicker.SetHeight(panelHeight)
+		m.panelHeight = panelHeight
 		return m, nil
 
 	case tea.MouseMsg:
@@ -309,19 +435,21 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		case stateSaveFilepath:
 			return updatePathInput(msg, m)
-		case stateSelectModel, stateSelectQType:
+		case stateSelectRecent, stateSelectProvider, stateSelectModel, stateSelectQType, stateSelectDifficulty, stateSelectGrade:
 			return updateListSelection(msg, m)
-		case stateEnterSentences:
+		case stateEnterMix:
+			return updateMixInput(msg, m)
+		case stateEnterSentences, stateEnterForms:
 			return updateNumInput(msg, m)
 		default:
 			return updateDefault(msg, m)
 		}
 
 	case tickMsg:
-		if m.isGenerating {
+		if m.isGenerating && msg.id == m.generationID {
 			m.generationSeconds++
-			m.status = fmt.Sprintf("Generating... (%ds)", m.generationSeconds)
-			return m, startGenerationTickerCmd() // Continue ticking
+			m.status = m.generatingStatus()
+			return m, startGenerationTickerCmd(m.generationID) // Continue ticking
 		}
 		return m, nil // Stop ticking
 
@@ -330,27 +458,116 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	
 	case fileReadMsg:
-		m.inputs[inputIdx].SetValue(string(msg.content))
+		text, format, count, skipped, err := importVocabText(msg.path, msg.content)
+		if err != nil {
+			m.status = fmt.Sprintf("Error: %v", err)
+			m.state = stateDefault
+			return m, nil
+		}
+		m.inputs[inputIdx].SetValue(text)
 		m.inputFilePath = msg.path
-		m.status = fmt.Sprintf("Loaded '%s'", filepath.Base(msg.path))
+		m.settings.rememberFile(msg.path)
+		if err := saveSettings(m.settings); err != nil {
+			m.logBuffer.WriteString(fmt.Sprintf("[%s] Failed to save settings: %v\n", time.Now().Format(time.RFC3339), err))
+		}
+		m.status = fmt.Sprintf("Loaded '%s' (%s, %d words)", filepath.Base(msg.path), format, count)
+		for _, d := range skipped {
+			m.logBuffer.WriteString(fmt.Sprintf("[%s] %s: %s\n", time.Now().Format(time.RFC3339), filepath.Base(msg.path), d))
+		}
+		if len(skipped) > 0 {
+			// Skipped rows are not in the input pane, so they are listed here.
+			m.status += fmt.Sprintf(" - %d rows skipped, first at %s", len(skipped), skipped[0])
+		}
+		if _, diags := parseVocabLines(text); len(diags) > 0 {
+			m.status += fmt.Sprintf(" - %d lines need attention. F8: next problem", len(diags))
+		}
 		m.state = stateDefault
+		if len(skipped) > 0 {
+			return m, resetErrorStatusCmd()
+		}
 		return m, resetSuccessStatusCmd()
 
 	case fileWriteMsg:
 		m.status = fmt.Sprintf("Saved to '%s'", filepath.Base(msg.path))
+		if msg.keyPath != "" {
+			m.status = fmt.Sprintf("Saved student paper to '%s' and answer key to '%s'", filepath.Base(msg.path), filepath.Base(msg.keyPath))
+		}
 		m.state = stateDefault
 		return m, resetSuccessStatusCmd()
 
+	case generationDeltaMsg:
+		if msg.id != m.generationID || !m.isGenerating {
+			// Late output of a cancelled run; keep draining so the goroutine can finish.
+			return m, waitForGenerationCmd(msg.ch)
+		}
+		if m.streamStale {
+			m.streamStale = false
+			m.inputs[outputIdx].Reset()
+		}
+		m.inputs[outputIdx].SetValue(m.inputs[outputIdx].Value() + msg.text)
+		return m, waitForGenerationCmd(msg.ch)
+
+	case generationRetryMsg:
+		if msg.id == m.generationID && m.isGenerating {
+			// Chunk and regeneration requests do not stream, so only the retried
+			// streaming request clears the pane, once its new text arrives.
+			m.streamStale = true
+			m.retryStatus = fmt.Sprintf(" [retry %d/%d in %ds: %v]", msg.attempt, msg.maxRetries, int(msg.wait.Round(time.Second)/time.Second), msg.err)
+			m.status = m.generatingStatus()
+			m.logBuffer.WriteString(fmt.Sprintf("[%s] Retry %d/%d after %s: %v\n", time.Now().Format(time.RFC3339), msg.attempt, msg.maxRetries, msg.wait, msg.err))
+		}
+		return m, waitForGenerationCmd(msg.ch)
+
+	case generationProgressMsg:
+		if msg.id == m.generationID && m.isGenerating {
+			m.progressStatus = fmt.Sprintf(" [chunks %d/%d]", msg.done, msg.total)
+			m.status = m.generatingStatus()
+		}
+		return m, waitForGenerationCmd(msg.ch)
+
+	case generationRegenMsg:
+		if msg.id == m.generationID && m.isGenerating {
+			m.progressStatus = fmt.Sprintf(" [regenerating %d words]", len(msg.words))
+			m.status = m.generatingStatus()
+		}
+		return m, waitForGenerationCmd(msg.ch)
+
 	case generationResultMsg:
+		if msg.id != m.generationID || !m.isGenerating {
+			return m, nil
+		}
 		m.isGenerating = false
+		m.cancelGeneration()
 		if msg.err != nil {
+			// Generation errors stay on screen until the next status change;
+			// they usually need action (fix the key, top up credit, shorten the list).
 			m.status = fmt.Sprintf("Generation Error: %v", msg.err)
-			return m, resetErrorStatusCmd()
+			return m, nil
 		} else {
 			m.status = "Generation complete!"
-			m.inputs[outputIdx].SetValue(msg.text)
+			m.state = stateDefault
+			m.inputs[outputIdx].SetValue(msg.result.Text)
+			m.questions = msg.result.Questions
+			m.forms = nil
+			if msg.result.Seed != 0 {
+				m.logBuffer.WriteString(fmt.Sprintf("[%s] Answers balanced with seed %d: %v\n", time.Now().Format(time.RFC3339), msg.result.Seed, answerDistribution(m.questions)))
+			}
+			if err := msg.result.ParseErr; err != nil {
+				m.logBuffer.WriteString(fmt.Sprintf("[%s] Question parse errors:\n%v\n", time.Now().Format(time.RFC3339), err))
+				m.status = fmt.Sprintf("Generation complete! (%d questions parsed, malformed blocks left out: %v)", len(m.questions), firstLine(err.Error()))
+			}
+			if issues := msg.result.Issues; len(issues) > 0 {
+				m.logBuffer.WriteString(fmt.Sprintf("[%s] Validation issues:\n", time.Now().Format(time.RFC3339)))
+				for _, is := range issues {
+					m.logBuffer.WriteString("  " + is.String() + "\n")
+				}
+				m.status = fmt.Sprintf("Generation complete with %d validation issues. First: %s", len(issues), issues[0])
+			}
+			if msg.result.ParseErr != nil || len(msg.result.Issues) > 0 {
+				// Keep warnings visible like errors; the full list is in the debug log.
+				return m, nil
+			}
 		}
-		m.state = stateDefault
 		return m, resetSuccessStatusCmd()
 
 	case debugFileWrittenMsg:
@@ -367,6 +584,13 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// The file picker reads directories asynchronously; route its messages to it.
+	if m.state == stateFilePicker {
+		var fpCmd tea.Cmd
+		m.filepicker, fpCmd = m.filepicker.Update(msg)
+		cmds = append(cmds, fpCmd)
+	}
+
 	// Update focused textarea in default state
 	if m.state == stateDefault {
 		var taCmd tea.Cmd
@@ -386,12 +610,19 @@ func updateDefault(msg tea.KeyMsg, m *model) (tea.Model, tea.Cmd) {
 	oldValue := m.inputs[m.focused].Value()
 
 	switch msg.String() {
+	case "esc", "ctrl+x":
+		if m.isGenerating {
+			m.stopGeneration()
+			m.status = "Generation cancelled."
+			return m, resetSuccessStatusCmd()
+		}
 	case "ctrl+z":
 		if len(m.undoHistory[m.focused]) > 0 {
 			lastState := m.undoHistory[m.focused][len(m.undoHistory[m.focused])-1]
 			m.undoHistory[m.focused] = m.undoHistory[m.focused][:len(m.undoHistory[m.focused])-1]
 			m.redoHistory[m.focused] = append(m.redoHistory[m.focused], oldValue)
 			m.inputs[m.focused].SetValue(lastState)
+			m.outputEdited()
 		}
 		return m, nil
 	case "ctrl+y":
@@ -400,45 +631,82 @@ func updateDefault(msg tea.KeyMsg, m *model) (tea.Model, tea.Cmd) {
 			m.redoHistory[m.focused] = m.redoHistory[m.focused][:len(m.redoHistory[m.focused])-1]
 			m.undoHistory[m.focused] = append(m.undoHistory[m.focused], oldValue)
 			m.inputs[m.focused].SetValue(lastState)
+			m.outputEdited()
+		}
+		return m, nil
+	case "f8", "shift+f8":
+		d, ok := m.jumpToDiagnostic(msg.String() == "f8")
+		if !ok {
+			m.status = "No problems found in the vocabulary list."
+			return m, resetSuccessStatusCmd()
 		}
+		m.status = d.String()
 		return m, nil
+
 	case "ctrl+o":
-		// 임시 해결책: word.txt 직접 로드
-		filePath := "word.txt" // word.txt가 현재 작업 디렉토리에 있다고 가정
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			m.status = fmt.Sprintf("Error loading word.txt: %v", err)
-			return m, nil
+		m.filepicker = m.newFilePicker()
+		m.state = stateFilePicker
+		return m, m.filepicker.Init()
+
+	case "ctrl+r":
+		if len(m.settings.RecentFiles) == 0 {
+			m.status = "No recent files yet. Ctrl+O: Load"
+			return m, resetSuccessStatusCmd()
 		}
-		m.inputFilePath = filePath // Use m.inputFilePath
-		m.inputs[inputIdx].SetValue(string(content)) // Use m.inputs[inputIdx]
-		m.state = stateDefault // 로드 후 기본 상태로 돌아감
-		m.status = fmt.Sprintf("Loaded: %s", filePath)
-		return m, resetSuccessStatusCmd()
+		m.state = stateSelectRecent
+		m.list.Title = "Recent Files"
+		m.list.SetItems(getRecentFiles(m.settings.RecentFiles))
+		return m, nil
 
 	case "ctrl+s":
 		m.state = stateSaveFilepath
 		originalName := "result"
 		if m.inputFilePath != "" {
-			originalName = strings.TrimSuffix(filepath.Base(m.inputFilePath), ".txt")
+			base := filepath.Base(m.inputFilePath)
+			originalName = strings.TrimSuffix(base, filepath.Ext(base))
 		}
 		m.pathInput.SetValue(fmt.Sprintf("%s_problem.txt", originalName))
 		m.pathInput.Focus()
 		m.status = "Enter file path to save."
 		return m, nil
 
+	case "ctrl+f":
+		if m.isGenerating {
+			m.status = "Generation in progress. Esc: cancel"
+			return m, nil
+		}
+		if len(m.questions) == 0 {
+			m.status = "No questions to make forms from: generate first."
+			return m, resetErrorStatusCmd()
+		}
+		m.state = stateEnterForms
+		if m.numForms == "" {
+			m.numForms = "3"
+		}
+		m.numInput.SetValue(m.numForms)
+		m.numInput.Focus()
+		m.status = "Enter number of forms."
+		return m, nil
+
 	case "ctrl+g":
+		if m.isGenerating {
+			m.status = "Generation already in progress. Esc: cancel"
+			return m, nil
+		}
 		if m.inputs[inputIdx].Value() == "" {
 			m.status = "Cannot generate: Input vocabulary is empty."
 			return m, resetErrorStatusCmd()
 		}
-		if m.apiKey == "" {
-			m.status = "Cannot generate: API Key is not configured in api.json."
-			return m, resetErrorStatusCmd()
+		providers := availableProviders(m.config)
+		if len(providers) == 0 {
+			// Without an API key only the question types built from the list are available.
+			m.selectModelFor(providerOffline)
+			m.status = "No API key in api.json: offline question types only."
+			return m, nil
 		}
-		m.state = stateSelectModel
-		m.list.Title = "Select a Model"
-		m.list.SetItems(getGenerationModels())
+		m.state = stateSelectProvider
+		m.list.Title = "Select a Provider"
+		m.list.SetItems(getProviders(append(providers, providerOffline)))
 		return m, nil
 
 	case "tab":
@@ -462,11 +730,20 @@ func updateDefault(msg tea.KeyMsg, m *model) (tea.Model, tea.Cmd) {
 	if newValue != oldValue {
 		m.undoHistory[m.focused] = append(m.undoHistory[m.focused], oldValue)
 		m.redoHistory[m.focused] = nil // Clear redo history on new action
+		m.outputEdited()
 	}
 
 	return m, cmd
 }
 
+// outputEdited forgets the forms once the output pane is edited or undone, so
+// they are not taken for the paper being saved.
+func (m *model) outputEdited() {
+	if m.focused == outputIdx {
+		m.forms = nil
+	}
+}
+
 func updatePathInput(msg tea.KeyMsg, m *model) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg.String() {
@@ -475,7 +752,11 @@ func updatePathInput(msg tea.KeyMsg, m *model) (tea.Model, tea.Cmd) {
 		if path == "" { return m, nil }
 		m.state = stateDefault
 		m.status = "Saving..."
-		return m, writeFileCmd(path, m.inputs[outputIdx].Value())
+		paper, key, explained := paperAndKey(m.inputs[outputIdx].Value(), m.questions, m.forms, m.vocab, m.config.AnswerExplanations)
+		if m.config.AnswerExplanations && !explained && len(m.questions) > 0 {
+			m.logBuffer.WriteString(fmt.Sprintf("[%s] The output pane differs from the generated questions; the answer key is saved as it is, without explanations.\n", time.Now().Format(time.RFC3339)))
+		}
+		return m, writePaperCmd(path, paper, key, m.config.Print)
 	case "esc":
 		m.state = stateDefault
 		m.status = "Cancelled save."
@@ -496,79 +777,377 @@ func updateListSelection(msg tea.KeyMsg, m *model) (tea.Model, tea.Cmd) {
 		return m, nil
 	case "enter":
 		item := m.list.SelectedItem().(item)
-		if m.state == stateSelectModel {
+		if m.state == stateSelectRecent {
+			m.state = stateDefault
+			if _, err := os.Stat(item.id); err != nil {
+				m.settings.RecentFiles = removeString(m.settings.RecentFiles, item.id)
+				saveSettings(m.settings)
+				m.status = fmt.Sprintf("Cannot open recent file: %v", err)
+				return m, resetErrorStatusCmd()
+			}
+			return m, readFileCmd(item.id)
+		} else if m.state == stateSelectProvider {
+			m.selectModelFor(item.id)
+		} else if m.state == stateSelectModel {
 			m.selectedModel = item.id
 			m.state = stateSelectQType
 			m.list.Title = "Select Question Type"
-			m.list.SetItems(getQTypes())
+			m.list.SetItems(getQTypes(false))
 			if item.desc == "Warning: High Cost" {
 				m.status = "Warning: High cost model selected!"
 			}
 		} else if m.state == stateSelectQType {
 			m.selectedQType = item.id
-			if m.selectedQType == "빈칸 추론" {
-				m.state = stateEnterSentences
-				m.numInput.SetValue(m.numSentences)
-				m.numInput.Focus()
-				m.status = "Enter number of sentences."
-			} else {
-				m.state = stateDefault
-				m.isGenerating = true
-				m.generationSeconds = 0
-				m.status = "Generating..."
-				parsed := parseVocabBlock(m.inputs[inputIdx].Value())
-				// Shuffle the parsed list to diagnose potential API truncation
-				rand.Seed(time.Now().UnixNano())
-				rand.Shuffle(len(parsed), func(i, j int) {
-					parsed[i], parsed[j] = parsed[j], parsed[i]
-				})
-				system, user := buildPrompts(parsed, m.selectedQType, 1)
-				m.logBuffer.WriteString(fmt.Sprintf("PROMPT_SYSTEM: %s\n", system))
-				m.logBuffer.WriteString(fmt.Sprintf("PROMPT_USER: %s\n", user))
-				return m, tea.Batch(generateCmd(m.apiKey, m.selectedModel, system, user), startGenerationTickerCmd())
+			m.mix = nil
+			if item.id == mixedQuestionType {
+				m.state = stateEnterMix
+				mix := m.settings.Mix
+				if mix == "" {
+					mix = defaultMixes[m.selectedProvider == providerOffline]
+				}
+				m.mixInput.SetValue(mix)
+				m.mixInput.Focus()
+				m.status = "Enter question types and shares."
+				return m, nil
 			}
+			if qt, _ := findQuestionType(item.id); qt.Local != nil {
+				// Offline types are built from the list alone; the level does not apply.
+				return m, m.enterSentencesOrStart()
+			}
+			m.selectDifficulty()
+		} else if m.state == stateSelectDifficulty {
+			m.learner.Difficulty = item.id
+			lvl, _ := findDifficulty(item.id)
+			grade := lvl.Grade
+			if m.settings.Difficulty == item.id && m.settings.Grade != 0 {
+				grade = m.settings.Grade
+			}
+			m.state = stateSelectGrade
+			m.list.Title = "Select Target Grade (" + item.id + ")"
+			m.list.SetItems(getGrades())
+			m.list.Select(indexOfItem(m.list.Items(), strconv.Itoa(grade)))
+		} else if m.state == stateSelectGrade {
+			m.learner.Grade, _ = strconv.Atoi(item.id)
+			m.settings.Difficulty, m.settings.Grade = m.learner.Difficulty, m.learner.Grade
+			saveSettings(m.settings)
+			return m, m.enterSentencesOrStart()
 		}
 		return m, nil
 	case "esc":
-		m.isGenerating = false
+		if m.state == stateSelectRecent {
+			m.status = "File selection cancelled."
+		} else {
+			m.status = "Cancelled generation."
+		}
 		m.state = stateDefault
-		m.status = "Cancelled generation."
 		return m, resetSuccessStatusCmd()
 	}
 	m.list, cmd = m.list.Update(msg)
 	return m, cmd
 }
 
+func updateMixInput(msg tea.KeyMsg, m *model) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "enter":
+		mix, err := parseMix(m.mixInput.Value())
+		if err == nil && m.selectedProvider == providerOffline {
+			for _, p := range mix {
+				if qt, _ := findQuestionType(p.QuestionType); qt.Local == nil {
+					err = fmt.Errorf("%q needs a model; offline types only", p.QuestionType)
+					break
+				}
+			}
+		}
+		if err != nil {
+			m.status = fmt.Sprintf("Mix Error: %v", err)
+			return m, nil
+		}
+		m.mix = mix
+		m.settings.Mix = formatMix(mix)
+		saveSettings(m.settings)
+		if m.selectedProvider == providerOffline {
+			return m, m.enterSentencesOrStart()
+		}
+		m.selectDifficulty()
+		return m, nil
+	case "esc":
+		m.state = stateDefault
+		m.status = "Cancelled generation."
+		return m, resetSuccessStatusCmd()
+	}
+	m.mixInput, cmd = m.mixInput.Update(msg)
+	return m, cmd
+}
+
 func updateNumInput(msg tea.KeyMsg, m *model) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg.String() {
 	case "enter":
+		if m.state == stateEnterForms {
+			return m, m.makeForms()
+		}
 		m.numSentences = m.numInput.Value()
-		m.state = stateDefault
-		m.isGenerating = true
-		m.generationSeconds = 0
-		m.status = "Generating..."
 		num, _ := strconv.Atoi(m.numSentences)
-		parsed := parseVocabBlock(m.inputs[inputIdx].Value())
-		// Shuffle the parsed list to diagnose potential API truncation
-		rand.Seed(time.Now().UnixNano())
-		rand.Shuffle(len(parsed), func(i, j int) {
-			parsed[i], parsed[j] = parsed[j], parsed[i]
-		})
-		system, user := buildPrompts(parsed, m.selectedQType, num)
-		m.logBuffer.WriteString(fmt.Sprintf("PROMPT_SYSTEM: %s\n", system))
-		m.logBuffer.WriteString(fmt.Sprintf("PROMPT_USER: %s\n", user))
-		return m, tea.Batch(generateCmd(m.apiKey, m.selectedModel, system, user), startGenerationTickerCmd())
+		return m, m.startGeneration(num)
 	case "esc":
-		m.isGenerating = false
+		if m.state == stateEnterForms {
+			m.status = "Cancelled forms."
+		} else {
+			m.status = "Cancelled generation."
+		}
 		m.state = stateDefault
-		m.status = "Cancelled generation."
 		return m, resetSuccessStatusCmd()
 	}
 	m.numInput, cmd = m.numInput.Update(msg)
 	return m, cmd
 }
 
+// makeForms replaces the output pane with the shuffled forms of the last
+// generated paper, their answer keys and the cross-reference table.
+func (m *model) makeForms() tea.Cmd {
+	n, err := strconv.Atoi(m.numInput.Value())
+	if err != nil || n < 2 || n > maxForms {
+		m.status = fmt.Sprintf("Enter a number of forms from 2 to %d.", maxForms)
+		return nil
+	}
+	m.numForms = m.numInput.Value()
+	m.state = stateDefault
+	seed := m.config.AnswerSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	m.forms = makeForms(m.questions, n, m.config.AnswerDistribution, seed)
+	m.logBuffer.WriteString(fmt.Sprintf("[%s] Made %d forms with seed %d\n", time.Now().Format(time.RFC3339), n, seed))
+	if old := m.inputs[outputIdx].Value(); old != "" {
+		m.undoHistory[outputIdx] = append(m.undoHistory[outputIdx], old)
+	}
+	m.inputs[outputIdx].SetValue(formatForms(m.forms))
+	m.status = fmt.Sprintf("Made forms A–%s with their answer keys and a cross-reference table.", m.forms[n-1].Name)
+	return resetSuccessStatusCmd()
+}
+
+// selectDifficulty moves to the difficulty list, on the level chosen last time.
+func (m *model) selectDifficulty() {
+	m.state = stateSelectDifficulty
+	m.list.Title = "Select Difficulty"
+	m.list.SetItems(getDifficulties())
+	m.list.Select(indexOfItem(m.list.Items(), m.settings.Difficulty))
+}
+
+// enterSentencesOrStart asks for the number of sentences if the chosen question
+// type, or a type of the mix, uses it, and starts generating otherwise.
+func (m *model) enterSentencesOrStart() tea.Cmd {
+	if qt, _ := findQuestionType(m.selectedQType); qt.Sentences > 0 || mixSentences(m.mix) {
+		m.state = stateEnterSentences
+		m.numInput.SetValue(m.numSentences)
+		m.numInput.Focus()
+		m.status = "Enter number of sentences."
+		return nil
+	}
+	return m.startGeneration(1)
+}
+
+// selectModelFor moves to the model list of the chosen provider. The offline
+// provider has no models and goes straight to its question types.
+func (m *model) selectModelFor(provider string) {
+	m.selectedProvider = provider
+	if provider == providerOffline {
+		m.selectedModel = ""
+		m.state = stateSelectQType
+		m.list.Title = "Select Question Type (offline)"
+		m.list.SetItems(getQTypes(true))
+		return
+	}
+	m.state = stateSelectModel
+	m.list.Title = "Select a Model"
+	m.list.SetItems(getGenerationModels(provider, m.config))
+}
+
+// startGeneration builds the prompts from the input pane and fires the request
+// against the selected provider and model.
+func (m *model) startGeneration(numSentences int) tea.Cmd {
+	m.state = stateDefault
+	var gen Generator
+	if m.selectedProvider != providerOffline {
+		var err error
+		if gen, err = newGenerator(m.selectedProvider, m.config); err != nil {
+			m.status = fmt.Sprintf("Generation Error: %v", err)
+			return resetErrorStatusCmd()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelGeneration = cancel
+	m.generationID++
+	m.isGenerating = true
+	m.generationSeconds = 0
+	m.retryStatus = ""
+	m.progressStatus = ""
+	m.streamStale = false
+	m.status = "Generating... (Esc: cancel)"
+	if old := m.inputs[outputIdx].Value(); old != "" {
+		m.undoHistory[outputIdx] = append(m.undoHistory[outputIdx], old)
+	}
+	m.inputs[outputIdx].Reset()
+	parsed, diags := parseVocabLines(m.inputs[inputIdx].Value())
+	for _, d := range diags {
+		m.logBuffer.WriteString(fmt.Sprintf("[%s] Vocabulary %s\n", time.Now().Format(time.RFC3339), d))
+	}
+	// Shuffle the parsed list to diagnose potential API truncation
+	rand.Seed(time.Now().UnixNano())
+	rand.Shuffle(len(parsed), func(i, j int) {
+		parsed[i], parsed[j] = parsed[j], parsed[i]
+	})
+	m.vocab = parsed
+	m.questions = nil
+	m.forms = nil
+	job := GenerationJob{
+		Model:        m.selectedModel,
+		QuestionType: m.selectedQType,
+		NumSentences: numSentences,
+		Vocab:        parsed,
+		Learner:      m.learner,
+		Mix:          m.mix,
+		ChunkSize:    m.config.ChunkSize,
+		Concurrency:  m.config.Concurrency,
+		Structured:   m.config.StructuredOutput,
+
+		MaxRegenerations: defaultMaxRegenerations,
+		AnswerWeights:    m.config.AnswerDistribution,
+		AnswerSeed:       m.config.AnswerSeed,
+	}
+	if m.config.MaxRegenerations != nil {
+		job.MaxRegenerations = *m.config.MaxRegenerations
+	}
+	// A prompt that fails to render is reported by the generation itself.
+	prompts, _ := jobPrompts(job)
+	for _, p := range prompts {
+		m.logBuffer.WriteString(fmt.Sprintf("PROMPT_SYSTEM: %s\n", p.system))
+		m.logBuffer.WriteString(fmt.Sprintf("PROMPT_USER: %s\n", p.user))
+	}
+	return tea.Batch(generateCmd(ctx, m.generationID, gen, job), startGenerationTickerCmd(m.generationID))
+}
+
+// jumpToDiagnostic moves the input cursor to the next (or previous) line that
+// has a diagnostic, wrapping around the list, and returns that diagnostic.
+func (m *model) jumpToDiagnostic(forward bool) (VocabDiagnostic, bool) {
+	ta := &m.inputs[inputIdx]
+	_, diags := parseVocabLines(ta.Value())
+	if len(diags) == 0 {
+		return VocabDiagnostic{}, false
+	}
+
+	cur := ta.Line() + 1
+	target := diags[0]
+	if forward {
+		for _, d := range diags {
+			if d.Line > cur {
+				target = d
+				break
+			}
+		}
+	} else {
+		target = diags[len(diags)-1]
+		for i := len(diags) - 1; i >= 0; i-- {
+			if diags[i].Line < cur {
+				target = diags[i]
+				break
+			}
+		}
+	}
+
+	if m.focused != inputIdx {
+		m.inputs[m.focused].Blur()
+		m.focused = inputIdx
+		ta.Focus()
+	}
+	// The textarea only moves by visual rows, so step until the logical line matches.
+	for i := 0; ta.Line() < target.Line-1 && i < 100000; i++ {
+		ta.CursorDown()
+	}
+	for i := 0; ta.Line() > target.Line-1 && i < 100000; i++ {
+		ta.CursorUp()
+	}
+	ta.CursorStart()
+	return target, true
+}
+
+// diagnosticSummary describes the input problems for the line above the panes,
+// preferring the problem on the cursor line.
+func (m *model) diagnosticSummary() string {
+	_, diags := parseVocabLines(m.inputs[inputIdx].Value())
+	if len(diags) == 0 {
+		return ""
+	}
+	shown := diags[0]
+	cur := m.inputs[inputIdx].Line() + 1
+	for _, d := range diags {
+		if d.Line == cur {
+			shown = d
+			break
+		}
+	}
+	return diagnosticStyle.Render(fmt.Sprintf("%d problems | %s | F8/Shift+F8: jump", len(diags), shown))
+}
+
+// markDiagnosticLines renders the textarea with the line number of every line
+// that has a diagnostic highlighted: red for errors, orange for warnings.
+func markDiagnosticLines(ta textarea.Model, diags []VocabDiagnostic) string {
+	view := ta.View()
+	if len(diags) == 0 {
+		return view
+	}
+	severity := make(map[int]Severity)
+	for _, d := range diags {
+		if s, ok := severity[d.Line]; !ok || d.Severity > s {
+			severity[d.Line] = d.Severity
+		}
+	}
+	// The line number field, e.g. " 12 ", follows the left border and the
+	// prompt. Wrapped rows leave it blank.
+	start := 1 + lipgloss.Width(ta.Prompt)
+	end := start + len(strconv.Itoa(ta.MaxHeight)) + 2
+	rows := strings.Split(view, "\n")
+	for i, row := range rows {
+		field := ansi.Strip(ansi.Cut(row, start, end))
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			continue
+		}
+		sev, ok := severity[n]
+		if !ok {
+			continue
+		}
+		style := diagnosticWarningLine
+		if sev == SeverityError {
+			style = diagnosticErrorLine
+		}
+		rows[i] = ansi.Cut(row, 0, start) + style.Render(field) + ansi.Cut(row, end, ansi.StringWidth(row))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " ..."
+	}
+	return s
+}
+
+func (m *model) generatingStatus() string {
+	return fmt.Sprintf("Generating... (%ds)%s%s Esc: cancel", m.generationSeconds, m.progressStatus, m.retryStatus)
+}
+
+// stopGeneration aborts the in-flight request. Any message it still produces
+// is ignored because isGenerating is false afterwards.
+func (m *model) stopGeneration() {
+	if m.cancelGeneration != nil {
+		m.cancelGeneration()
+	}
+	m.isGenerating = false
+}
+
 // --- View ---
 
 func (m *model) View() string {
@@ -578,20 +1157,30 @@ func (m *model) View() string {
 
 	switch m.state {
 	case stateFilePicker:
-		return docStyle.Render(m.filepicker.View())
+		header := fmt.Sprintf("Select a vocabulary file (%s)\n%s\n", strings.Join(vocabFileExtensions, ", "), m.filepicker.CurrentDirectory)
+		return docStyle.Render(header + "\n" + m.filepicker.View() + "\n" + helpStyle.Render("Enter/→: open | ←/Backspace: parent dir | Esc: cancel"))
 	case stateSaveFilepath:
-		return docStyle.Render(fmt.Sprintf("Save file as:\n\n%s", m.pathInput.View()) + "\n\nEnter: confirm | Esc: cancel")
-	case stateSelectModel, stateSelectQType:
+		return docStyle.Render(fmt.Sprintf("Save file as (.txt, or .html/.pdf for printing):\n\n%s", m.pathInput.View()) + "\n\nEnter: confirm | Esc: cancel")
+	case stateSelectRecent, stateSelectProvider, stateSelectModel, stateSelectQType, stateSelectDifficulty, stateSelectGrade:
 		return docStyle.Render(m.list.View())
+	case stateEnterMix:
+		return docStyle.Render(fmt.Sprintf("Enter question types and shares, e.g. %q:\n\n%s", defaultMixes[false], m.mixInput.View()) + "\n\nEnter: confirm | Esc: cancel")
+	case stateEnterForms:
+		return docStyle.Render(fmt.Sprintf("Enter number of test forms (2–%d):\n\n%s", maxForms, m.numInput.View()) + "\n\nEnter: confirm | Esc: cancel")
 	case stateEnterSentences:
 		return docStyle.Render(fmt.Sprintf("Enter number of sentences:\n\n%s", m.numInput.View()) + "\n\nEnter: confirm | Esc: cancel")
 	default:
-		var topContent string
+		var topParts []string
 		if m.inputFilePath != "" {
-			topContent = fmt.Sprintf("Loaded File: %s", filepath.Base(m.inputFilePath))
+			topParts = append(topParts, fmt.Sprintf("Loaded File: %s", filepath.Base(m.inputFilePath)))
+		}
+		if summary := m.diagnosticSummary(); summary != "" {
+			topParts = append(topParts, summary)
 		}
+		topContent := strings.Join(topParts, "  ")
 
-		panels := lipgloss.JoinHorizontal(lipgloss.Top, m.inputs[inputIdx].View(), m.inputs[outputIdx].View())
+		_, diags := parseVocabLines(m.inputs[inputIdx].Value())
+		panels := lipgloss.JoinHorizontal(lipgloss.Top, markDiagnosticLines(m.inputs[inputIdx], diags), m.inputs[outputIdx].View())
 		
 		contentStack := []string{}
 		if topContent != "" {
@@ -613,7 +1202,90 @@ func (i item) Title() string       { return i.title }
 func (i item) Description() string { return i.desc }
 func (i item) FilterValue() string { return i.title }
 
-func getGenerationModels() []list.Item {
+func removeString(list []string, s string) []string {
+	var out []string
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func getRecentFiles(paths []string) []list.Item {
+	var items []list.Item
+	for _, p := range paths {
+		items = append(items, item{title: filepath.Base(p), id: p, desc: filepath.Dir(p)})
+	}
+	return items
+}
+
+func getProviders(providers []string) []list.Item {
+	titles := map[string]string{
+		providerOpenAI:    "OpenAI",
+		providerAnthropic: "Anthropic",
+		providerGemini:    "Google Gemini",
+		providerLocal:     "Local Server (Ollama / llama.cpp)",
+		providerOffline:   "Offline (no API, built from the list)",
+	}
+	var items []list.Item
+	for _, p := range providers {
+		items = append(items, item{title: titles[p], id: p})
+	}
+	return items
+}
+
+func getDifficulties() []list.Item {
+	var items []list.Item
+	for _, lvl := range difficultyLevels {
+		items = append(items, item{title: lvl.ID, id: lvl.ID, desc: lvl.Description})
+	}
+	return items
+}
+
+func getGrades() []list.Item {
+	var items []list.Item
+	for _, g := range learnerGrades {
+		items = append(items, item{title: fmt.Sprintf("%s (grade %d)", g.Title, g.Grade), id: strconv.Itoa(g.Grade), desc: "CEFR " + g.CEFR})
+	}
+	return items
+}
+
+// indexOfItem returns the position of the item with the given id, or 0.
+func indexOfItem(items []list.Item, id string) int {
+	for i, it := range items {
+		if it.(item).id == id {
+			return i
+		}
+	}
+	return 0
+}
+
+func getGenerationModels(provider string, cfg APIConfig) []list.Item {
+	switch provider {
+	case providerAnthropic:
+		return []list.Item{
+			item{title: "Claude Opus 4.1", id: "claude-opus-4-1", desc: "Warning: High Cost"},
+			item{title: "Claude Sonnet 4.5", id: "claude-sonnet-4-5"},
+			item{title: "Claude Haiku 4.5", id: "claude-haiku-4-5"},
+		}
+	case providerGemini:
+		return []list.Item{
+			item{title: "Gemini 2.5 Pro", id: "gemini-2.5-pro"},
+			item{title: "Gemini 2.5 Flash", id: "gemini-2.5-flash"},
+			item{title: "Gemini 2.5 Flash-Lite", id: "gemini-2.5-flash-lite"},
+		}
+	case providerLocal:
+		models := cfg.LocalModels
+		if len(models) == 0 {
+			models = []string{"llama3.1"}
+		}
+		var items []list.Item
+		for _, id := range models {
+			items = append(items, item{title: id, id: id, desc: localEndpoint(cfg)})
+		}
+		return items
+	}
 	return []list.Item{
 		item{title: "GPT-5 pro", id: "gpt-5-pro", desc: "Warning: High Cost"},
 		item{title: "GPT-5", id: "gpt-5"},
@@ -623,10 +1295,15 @@ func getGenerationModels() []list.Item {
 	}
 }
 
-func getQTypes() []list.Item {
-	return []list.Item{
-		item{title: "빈칸 추론", id: "빈칸 추론"},
-		item{title: "영영풀이", id: "영영풀이"},
-		item{title: "뜻풀이 판단", id: "뜻풀이 판단"},
+// getQTypes lists the question types of the offline provider, or those defined
+// by the prompt templates for the API providers, followed by the mixed test.
+func getQTypes(offline bool) []list.Item {
+	var items []list.Item
+	for _, qt := range questionTypes {
+		if (qt.Local != nil) == offline {
+			items = append(items, item{title: qt.ID, id: qt.ID, desc: qt.Description})
+		}
 	}
+	items = append(items, item{title: mixedQuestionType, id: mixedQuestionType, desc: "여러 유형을 비율대로 섞은 한 장의 시험지"})
+	return items
 }
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const defaultMaxRegenerations = 1
+
+// ValidationIssue is one problem found in a generated paper. Number is 0 for
+// list-level problems such as a word that got no question.
+type ValidationIssue struct {
+	Number int
+	Word   string
+	POS    string
+	Reason string
+}
+
+func (i ValidationIssue) String() string {
+	word := headword(VocabPair{Word: i.Word, POS: i.POS})
+	if i.Number == 0 {
+		return fmt.Sprintf("'%s': %s", word, i.Reason)
+	}
+	if i.Word == "" {
+		return fmt.Sprintf("%d번: %s", i.Number, i.Reason)
+	}
+	return fmt.Sprintf("%d번 ('%s'): %s", i.Number, word, i.Reason)
+}
+
+// validateQuestions checks the questions of one question type against the
+// vocabulary list they were generated from.
+func validateQuestions(questions []Question, vocab []VocabPair, questionType string) []ValidationIssue {
+	qt, _ := findQuestionType(questionType)
+	var issues []ValidationIssue
+	add := func(q Question, format string, args ...any) {
+		issues = append(issues, ValidationIssue{Number: q.Number, Word: q.Word, POS: q.POS, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	perEntry := make(map[string]int)
+	for _, q := range questions {
+		switch {
+		case len(q.Words) > 0:
+			for _, w := range q.Words {
+				perEntry[entryKey(splitHeadword(w))]++
+			}
+		case q.Word == "":
+			add(q, "어느 단어의 문제인지 알 수 없습니다")
+		default:
+			perEntry[q.key()]++
+		}
+
+		if qt.Underline && !q.Underlined {
+			add(q, "밑줄 친 부분(①[...]~⑤[...])이 없습니다")
+			continue
+		}
+		if qt.Passage > 0 && !qt.Underline {
+			validatePassage(q, add)
+			continue
+		}
+		if len(q.Choices) == 0 && q.AnswerText != "" {
+			// Written questions have no choices to check.
+			continue
+		}
+
+		if len(q.Choices) != numChoices {
+			add(q, "선택지가 %d개입니다 (5개 필요)", len(q.Choices))
+			continue
+		}
+		if hasDuplicateChoice(q.Choices) {
+			add(q, "중복된 선택지가 있습니다")
+		}
+		if q.Answer < 0 || q.Answer >= numChoices {
+			add(q, "정답이 없습니다")
+			continue
+		}
+
+		if q.Underlined {
+			// The marked parts come from the passage; the other checks are about listed choices.
+			continue
+		}
+		if qt.AnswerIsWord && q.Word != "" {
+			idx := indexOfChoice(q.Choices, q.Word)
+			switch {
+			case idx < 0:
+				add(q, "정답 단어가 선택지에 없습니다")
+			case idx != q.Answer:
+				add(q, "[정답]이 %s이지만 단어는 %s에 있습니다", choiceMarks[q.Answer], choiceMarks[idx])
+			}
+		}
+		if qt.ExcludeWord && q.Word != "" && indexOfChoice(q.Choices, q.Word) >= 0 {
+			add(q, "출제 단어 자체가 선택지에 있습니다")
+		}
+		if qt.Blanks {
+			for _, line := range q.Context {
+				if !strings.Contains(line, "___") {
+					add(q, "빈칸(_______)이 없는 문장이 있습니다: %q", line)
+					break
+				}
+			}
+			if len(q.Context) == 0 {
+				add(q, "빈칸 문장이 없습니다")
+			}
+		}
+	}
+
+	for _, v := range vocab {
+		n := perEntry[v.key()]
+		switch {
+		case n == 0:
+			issues = append(issues, ValidationIssue{Word: v.Word, POS: v.POS, Reason: "문제가 생성되지 않았습니다"})
+		case qt.PerSense && n < len(v.Meanings):
+			issues = append(issues, ValidationIssue{Word: v.Word, POS: v.POS, Reason: fmt.Sprintf("뜻 %d개 중 %d개만 출제되었습니다", len(v.Meanings), n)})
+		}
+	}
+	return issues
+}
+
+func indexOfChoice(choices []string, word string) int {
+	for i, c := range choices {
+		if strings.EqualFold(strings.TrimSpace(c), word) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasDuplicateChoice(choices []string) bool {
+	seen := make(map[string]bool)
+	for _, c := range choices {
+		key := strings.ToLower(strings.TrimSpace(c))
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+	}
+	return false
+}
+
+// failingWords returns the vocabulary entries that have at least one issue. An
+// issue of a passage counts for every word of the passage.
+func failingWords(issues []ValidationIssue, questions []Question, vocab []VocabPair) []VocabPair {
+	failing := make(map[string]bool)
+	for _, is := range issues {
+		if is.Word != "" {
+			failing[entryKey(is.Word, is.POS)] = true
+			continue
+		}
+		for _, q := range questions {
+			if q.Number == is.Number {
+				for _, w := range q.Words {
+					failing[entryKey(splitHeadword(w))] = true
+				}
+			}
+		}
+	}
+	var words []VocabPair
+	for _, v := range vocab {
+		if failing[v.key()] {
+			words = append(words, v)
+		}
+	}
+	return words
+}
+
+// replaceWordQuestions drops every question of the given words, and every
+// question with no known word, then appends the regenerated ones.
+func replaceWordQuestions(questions, regenerated []Question, words []VocabPair) []Question {
+	drop := make(map[string]bool)
+	for _, v := range words {
+		drop[v.key()] = true
+	}
+	var kept []Question
+	for _, q := range questions {
+		if !keepQuestion(q, drop) {
+			continue
+		}
+		kept = append(kept, q)
+	}
+	kept = append(kept, regenerated...)
+	renumberQuestions(kept)
+	return kept
+}
+
+// keepQuestion reports whether a question tests none of the dropped words. A
+// passage is dropped as a whole when any of its words is.
+func keepQuestion(q Question, drop map[string]bool) bool {
+	if len(q.Words) > 0 {
+		for _, w := range q.Words {
+			if drop[entryKey(splitHeadword(w))] {
+				return false
+			}
+		}
+		return true
+	}
+	return q.Word != "" && !drop[q.key()]
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateQuestions(t *testing.T) {
+	bank := VocabPair{Word: "bank", Meanings: []string{"은행"}}
+	choices := []string{"bank", "run", "fair", "bear", "light"}
+	blank := Question{Number: 1, Context: []string{"I went to the ___."}, Choices: choices, Answer: 0, Word: "bank"}
+	tests := []struct {
+		name         string
+		questionType string
+		questions    []Question
+		vocab        []VocabPair
+		want         []string
+	}{
+		{
+			name:         "valid",
+			questionType: "빈칸 추론",
+			questions:    []Question{blank},
+			vocab:        []VocabPair{bank},
+		},
+		{
+			name:         "missing question and unknown word",
+			questionType: "뜻풀이 판단",
+			questions:    []Question{{Number: 1, Choices: choices, Answer: 0}},
+			vocab:        []VocabPair{bank},
+			want:         []string{"1번: 어느 단어의 문제인지 알 수 없습니다", "'bank': 문제가 생성되지 않았습니다"},
+		},
+		{
+			name:         "choices",
+			questionType: "뜻풀이 판단",
+			questions: []Question{
+				{Number: 1, Choices: choices[:4], Answer: 0, Word: "bank"},
+				{Number: 2, Choices: []string{"bank", "Bank", "b", "c", "d"}, Answer: 0, Word: "bank"},
+				{Number: 3, Choices: choices, Answer: -1, Word: "bank"},
+			},
+			vocab: []VocabPair{bank},
+			want: []string{
+				"1번 ('bank'): 선택지가 4개입니다 (5개 필요)",
+				"2번 ('bank'): 중복된 선택지가 있습니다",
+				"3번 ('bank'): 정답이 없습니다",
+			},
+		},
+		{
+			name:         "answer is not the word",
+			questionType: "빈칸 추론",
+			questions:    []Question{{Number: 1, Context: []string{"___"}, Choices: choices, Answer: 2, Word: "bank"}},
+			vocab:        []VocabPair{bank},
+			want:         []string{"1번 ('bank'): [정답]이 ③이지만 단어는 ①에 있습니다"},
+		},
+		{
+			name:         "blank missing",
+			questionType: "빈칸 추론",
+			questions:    []Question{{Number: 1, Context: []string{"No blank here."}, Choices: choices, Answer: 0, Word: "bank"}},
+			vocab:        []VocabPair{bank},
+			want:         []string{`1번 ('bank'): 빈칸(_______)이 없는 문장이 있습니다: "No blank here."`},
+		},
+		{
+			name:         "word among synonyms",
+			questionType: "동의어 고르기",
+			questions:    []Question{{Number: 1, Choices: choices, Answer: 1, Word: "bank"}},
+			vocab:        []VocabPair{bank},
+			want:         []string{"1번 ('bank'): 출제 단어 자체가 선택지에 있습니다"},
+		},
+		{
+			name:         "every sense",
+			questionType: "빈칸 추론",
+			questions:    []Question{blank},
+			vocab:        []VocabPair{{Word: "bank", Meanings: []string{"은행", "둑"}}},
+			want:         []string{"'bank': 뜻 2개 중 1개만 출제되었습니다"},
+		},
+		{
+			name:         "entries of one word are counted apart",
+			questionType: "뜻풀이 판단",
+			questions:    []Question{{Number: 1, Choices: choices, Answer: 0, Word: "conduct", POS: "n."}},
+			vocab: []VocabPair{
+				{Word: "conduct", POS: "n.", Meanings: []string{"행동"}},
+				{Word: "conduct", POS: "v.", Meanings: []string{"수행하다"}},
+			},
+			want: []string{"'conduct (v.)': 문제가 생성되지 않았습니다"},
+		},
+		{
+			name:         "passage",
+			questionType: "지문 빈칸",
+			questions: []Question{{
+				Number: 1, Context: []string{"The (a) _______ and the (c) _______.", "[보기] bank / run"},
+				Answer: -1, AnswerText: "(a) bank (b) fair", Words: []string{"bank"},
+			}},
+			vocab: []VocabPair{bank},
+			want: []string{
+				"1번: 빈칸 (c)의 순서가 맞지 않습니다",
+				"1번: 정답 'fair'이(가) [보기]에 없습니다",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, is := range validateQuestions(tt.questions, tt.vocab, tt.questionType) {
+				got = append(got, is.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("issues = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailingWords(t *testing.T) {
+	vocab := []VocabPair{
+		{Word: "conduct", POS: "n.", Meanings: []string{"행동"}},
+		{Word: "conduct", POS: "v.", Meanings: []string{"수행하다"}},
+		{Word: "bank", Meanings: []string{"은행"}},
+		{Word: "run", Meanings: []string{"달리다"}},
+	}
+	questions := []Question{
+		{Number: 1, Word: "conduct", POS: "n."},
+		{Number: 2, Words: []string{"conduct (v.)", "run"}, AnswerText: "(a) conduct (b) run"},
+	}
+	tests := []struct {
+		name   string
+		issues []ValidationIssue
+		want   []string
+	}{
+		{"none", nil, nil},
+		{"one entry of a word", []ValidationIssue{{Number: 1, Word: "conduct", POS: "n."}}, []string{"conduct (n.)"}},
+		{"part of speech written differently", []ValidationIssue{{Word: "Conduct", POS: "V"}}, []string{"conduct (v.)"}},
+		{"every word of a passage", []ValidationIssue{{Number: 2}}, []string{"conduct (v.)", "run"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range failingWords(tt.issues, questions, vocab) {
+				got = append(got, headword(v))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("failingWords = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceWordQuestions(t *testing.T) {
+	questions := []Question{
+		{Number: 1, Word: "conduct", POS: "n."},
+		{Number: 2, Word: "conduct", POS: "v."},
+		{Number: 3, Words: []string{"conduct (v.)", "run"}},
+		{Number: 4, Word: "bank"},
+		{Number: 5},
+	}
+	regenerated := []Question{{Number: 1, Word: "conduct", POS: "v."}}
+	got := replaceWordQuestions(questions, regenerated, []VocabPair{{Word: "conduct", POS: "v."}})
+	want := []Question{
+		{Number: 1, Word: "conduct", POS: "n."},
+		{Number: 2, Word: "bank"},
+		{Number: 3, Word: "conduct", POS: "v."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}